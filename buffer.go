@@ -0,0 +1,94 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+const (
+	// defaultBufferSize is number of the latest logs kept per request for debug on error
+	defaultBufferSize = 100
+
+	// defaultBufferMaxRequests is number of requests kept for debug on error, the least recent is discarded first
+	defaultBufferMaxRequests = 10000
+
+	// bufferTTL is how long logs of a request are kept after its latest log when it never ends by DiscardBufferedLogs
+	bufferTTL = 5 * time.Minute
+)
+
+// bufferLog keeps log below minimum level of the request, to be written if the request fails
+func (l *activeLogger) bufferLog(level Level, field logger.Field, err error, message string, args ...interface{}) {
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+
+	// the caller may modify its maps once the log returns, the entry is written later from a snapshot
+	field.Source = snapshot(field.Source)
+	field.UserInfo = snapshot(field.UserInfo)
+	field.Metadata = snapshotMap(field.Metadata)
+	field.Fields = snapshotMap(field.Fields)
+
+	l.buffer.Add(field.RequestID, logger.Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Field:   field,
+		Err:     err,
+		Message: message,
+	})
+}
+
+// snapshot copies nested maps & slices of the value, other values (e.g. structs) are kept as is
+func snapshot(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return snapshotMap(v)
+	case KV:
+		return snapshotMap(v)
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		s := make([]interface{}, len(v))
+		for i, e := range v {
+			s[i] = snapshot(e)
+		}
+		return s
+	}
+	return value
+}
+
+func snapshotMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	s := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		s[k] = snapshot(v)
+	}
+	return s
+}
+
+// flushBuffered writes kept logs of the request at their own level & time
+func (l *activeLogger) flushBuffered(requestID string) {
+	for _, entry := range l.buffer.Take(requestID) {
+		l.logger.Write(entry)
+	}
+}
+
+// DiscardBufferedLogs discards logs kept for debug on error of the request in the context,
+// call it when the request ends (the HTTP middleware & gRPC interceptors do it for you)
+func DiscardBufferedLogs(ctx context.Context) {
+	requestID := GetCtxRequestID(ctx)
+	if requestID == "" {
+		return
+	}
+
+	l := acquire()
+	defer l.release()
+	if l.buffer != nil {
+		l.buffer.Discard(requestID)
+	}
+}
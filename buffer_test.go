@@ -0,0 +1,126 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func debugOnErrorConfig(t *testing.T, engine Engine) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "debug.log")
+	if err := SetConfig(&Config{Engine: engine, Level: InfoLevel, FilePath: path, UseJSON: true, DebugOnError: true}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetConfig(nil) })
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestBufferedLogSnapshot(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := debugOnErrorConfig(t, engine)
+
+			var (
+				userInfo = KV{"role": "guest"}
+				nested   = map[string]interface{}{"step": "buffered"}
+				metadata = KV{"nested": nested, "list": []interface{}{map[string]interface{}{"step": "buffered"}}}
+				ctx      = SetCtxUserInfo(SetCtxRequestID(context.Background(), "req-snapshot"), userInfo)
+			)
+			Debug(ctx, nil, metadata, "debug before error")
+
+			// the caller reuses its maps after the log returns
+			userInfo["role"] = "modified"
+			nested["step"] = "modified"
+			metadata["list"].([]interface{})[0].(map[string]interface{})["step"] = "modified"
+			metadata["added"] = "modified"
+
+			Error(ctx, nil, nil, "request failed")
+
+			var debug string
+			for _, line := range strings.Split(readFile(t, path), "\n") {
+				if strings.Contains(line, "debug before error") {
+					debug = line
+				}
+			}
+			if debug == "" {
+				t.Fatal("buffered log is not flushed")
+			}
+			if strings.Contains(debug, "modified") {
+				t.Errorf("buffered log is written with values modified after the log: %s", debug)
+			}
+		})
+	}
+}
+
+func TestBufferedLogConcurrentModify(t *testing.T) {
+	debugOnErrorConfig(t, Zap)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := SetCtxRequestID(context.Background())
+			metadata := KV{"n": 0}
+			for n := 0; n < 100; n++ {
+				Debug(ctx, nil, metadata, "debug")
+				metadata["n"] = n
+			}
+			var flushed sync.WaitGroup
+			flushed.Add(1)
+			go func() {
+				defer flushed.Done()
+				Error(ctx, nil, nil, "request failed")
+			}()
+			for n := 0; n < 100; n++ {
+				metadata["n"] = n
+			}
+			flushed.Wait()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSlogDebugOnError(t *testing.T) {
+	path := debugOnErrorConfig(t, Zerolog)
+	logger := NewSlogLogger()
+
+	ctx := context.Background()
+	if logger.Enabled(ctx, -4) {
+		t.Error("slog debug is enabled without a request")
+	}
+	logger.DebugContext(ctx, "slog debug without request")
+
+	ctx = SetCtxRequestID(ctx, "req-slog")
+	if !logger.Enabled(ctx, -4) {
+		t.Error("slog debug is not enabled for a request while debug on error is on")
+	}
+	logger.DebugContext(ctx, "slog debug before error")
+	logger.ErrorContext(ctx, "slog request failed")
+
+	out := readFile(t, path)
+	if !strings.Contains(out, "slog debug before error") {
+		t.Errorf("slog debug log is not flushed on error:\n%s", out)
+	}
+	if strings.Contains(out, "slog debug without request") {
+		t.Errorf("slog debug log without request is written:\n%s", out)
+	}
+}
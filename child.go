@@ -0,0 +1,210 @@
+package log
+
+import (
+	"context"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// reservedFieldPrefix prefixes fields of child logger whose key is written by go-log itself,
+// e.g. `request_id` is carried as `field_request_id`
+const reservedFieldPrefix = "field_"
+
+// ChildLogger is a logger which permanently carries its fields in every log
+type ChildLogger struct {
+	name   string
+	fields KV
+}
+
+// With creates a child logger carrying the fields (e.g. component, module, job id) in every log,
+// a field named like a key of go-log (e.g. `request_id` or `message`) is prefixed by `field_` instead of overwriting it
+func With(fields KV) *ChildLogger {
+	return (&ChildLogger{}).With(fields)
+}
+
+// With creates a child logger carrying the parent fields plus the given fields
+func (c *ChildLogger) With(fields KV) *ChildLogger {
+	childFields := make(KV, len(c.fields)+len(fields))
+	for key, value := range c.fields {
+		childFields[key] = value
+	}
+	for key, value := range fields {
+		if logger.IsReservedFieldName(key) {
+			key = reservedFieldPrefix + key
+		}
+		childFields[key] = value
+	}
+
+	return &ChildLogger{
+		name:   c.name,
+		fields: childFields,
+	}
+}
+
+func (c *ChildLogger) buildFields(ctx context.Context, metadata KV) logger.Field {
+	fields := buildFields(ctx, metadata)
+
+	if c.name != "" {
+		fields.Name = c.name
+		// minimum level of the context (e.g. debug for a single request) overrides the named one
+		if level, ok := rnamed.Level(c.name); ok && fields.MinLevel == nil {
+			fields.MinLevel = &level
+		}
+	}
+
+	if len(c.fields) > 0 {
+		// copied on every log, so the bound fields are never shared between logs
+		fields.Fields = make(map[string]interface{}, len(c.fields))
+		for key, value := range c.fields {
+			fields.Fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// Trace prints log on trace level, below debug for very chatty diagnostics
+func (c *ChildLogger) Trace(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(TraceLevel, fields, err, message) {
+		return
+	}
+	l.logger.Trace(fields, err, message)
+}
+
+// Debug prints log on debug level
+func (c *ChildLogger) Debug(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(DebugLevel, fields, err, message) {
+		return
+	}
+	l.logger.Debug(fields, err, message)
+}
+
+// Info prints log on info level
+func (c *ChildLogger) Info(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(InfoLevel, fields, err, message) {
+		return
+	}
+	l.logger.Info(fields, err, message)
+}
+
+// Warn prints log on warn level
+func (c *ChildLogger) Warn(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(WarnLevel, fields, err, message) {
+		return
+	}
+	l.logger.Warn(fields, err, message)
+}
+
+// Error prints log on error level
+func (c *ChildLogger) Error(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(ErrorLevel, fields, err, message) {
+		return
+	}
+	l.logger.Error(fields, err, message)
+}
+
+// Fatal prints log on fatal level
+func (c *ChildLogger) Fatal(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	l.admit(FatalLevel, fields, err, message)
+	l.logger.Fatal(fields, err, message)
+}
+
+// Panic prints log on panic level then panics, so deferred recovery & cleanup still run unlike Fatal
+func (c *ChildLogger) Panic(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	l.admit(PanicLevel, fields, err, message)
+	l.logger.Panic(fields, err, message)
+}
+
+// Tracef prints log on trace level like fmt.Printf
+func (c *ChildLogger) Tracef(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(TraceLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Tracef(fields, err, formatedMsg, args...)
+}
+
+// Debugf prints log on debug level like fmt.Printf
+func (c *ChildLogger) Debugf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(DebugLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Debugf(fields, err, formatedMsg, args...)
+}
+
+// Infof prints log on info level like fmt.Printf
+func (c *ChildLogger) Infof(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(InfoLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Infof(fields, err, formatedMsg, args...)
+}
+
+// Warnf prints log on warn level like fmt.Printf
+func (c *ChildLogger) Warnf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(WarnLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Warnf(fields, err, formatedMsg, args...)
+}
+
+// Errorf prints log on error level like fmt.printf
+func (c *ChildLogger) Errorf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	if !l.admit(ErrorLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Errorf(fields, err, formatedMsg, args...)
+}
+
+// Fatalf prints log on fatal level like fmt.printf
+func (c *ChildLogger) Fatalf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	l.admit(FatalLevel, fields, err, formatedMsg, args...)
+	l.logger.Fatalf(fields, err, formatedMsg, args...)
+}
+
+// Panicf prints log on panic level like fmt.Printf then panics
+func (c *ChildLogger) Panicf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := c.buildFields(ctx, metadata)
+	l.admit(PanicLevel, fields, err, formatedMsg, args...)
+	l.logger.Panicf(fields, err, formatedMsg, args...)
+}
@@ -0,0 +1,79 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestChildLoggerReservedFields(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := slogConfig(t, engine, false)
+
+			child := With(KV{
+				"request_id": "forged",
+				"message":    "forged",
+				"level":      "forged",
+				"timestamp":  "forged",
+				"metadata":   "forged",
+				"component":  "worker",
+			})
+			ctx := SetCtxRequestID(context.Background(), "req-1")
+			child.Error(ctx, errors.New("failed"), KV{"attempt": 1}, "job failed")
+
+			lines := readLines(t, path)
+			if len(lines) != 1 {
+				t.Fatalf("want 1 log, got %v", lines)
+			}
+			line := lines[0]
+			if line["request_id"] != "req-1" || line["message"] != "job failed" || line["level"] != "error" || line["timestamp"] == "forged" {
+				t.Errorf("child fields overwrite keys of go-log: %v", line)
+			}
+			if metadata, _ := line["metadata"].(map[string]interface{}); metadata["attempt"] != float64(1) {
+				t.Errorf("metadata = %v, want the metadata of the log", line["metadata"])
+			}
+			for _, key := range []string{"request_id", "message", "level", "timestamp", "metadata"} {
+				if line["field_"+key] != "forged" {
+					t.Errorf("field_%s = %v, want the prefixed child field", key, line["field_"+key])
+				}
+			}
+			if line["component"] != "worker" {
+				t.Errorf("component = %v, want worker", line["component"])
+			}
+		})
+	}
+}
+
+func TestChildLoggerWith(t *testing.T) {
+	path := slogConfig(t, Zerolog, false)
+
+	parent := With(KV{"component": "worker", "job_id": 1})
+	child := parent.With(KV{"job_id": 2, "step": "download"})
+	named := child.Named("jobs")
+	if want := (KV{"component": "worker", "job_id": 1}); !reflect.DeepEqual(parent.fields, want) {
+		t.Errorf("parent fields = %v after With, want %v", parent.fields, want)
+	}
+
+	ctx := context.Background()
+	parent.Info(ctx, nil, nil, "parent")
+	child.Info(ctx, nil, nil, "child")
+	named.Info(ctx, nil, nil, "named")
+
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("want 3 logs, got %v", lines)
+	}
+	if lines[0]["job_id"] != float64(1) || lines[0]["step"] != nil {
+		t.Errorf("parent log = %v, want only the parent fields", lines[0])
+	}
+	for _, line := range lines[1:] {
+		if line["component"] != "worker" || line["job_id"] != float64(2) || line["step"] != "download" {
+			t.Errorf("%v log = %v, want the parent fields overridden by the child", line["message"], line)
+		}
+	}
+	if lines[2]["logger"] != "jobs" || lines[1]["logger"] != nil {
+		t.Errorf("logger names = %v & %v, want only the named one", lines[1]["logger"], lines[2]["logger"])
+	}
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCloseFlushes(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		for _, async := range []bool{false, true} {
+			name := engine.String()
+			if async {
+				name += "/async"
+			}
+			t.Run(name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "close.log")
+				err := SetConfig(&Config{
+					Engine:         engine,
+					FilePath:       path,
+					UseJSON:        true,
+					UseAsync:       async,
+					AsyncQueueSize: 1000,
+					DedupWindow:    time.Minute,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer SetConfig(nil)
+
+				for i := 0; i < 500; i++ {
+					Infof(context.Background(), nil, nil, "log %d", i)
+				}
+				Info(context.Background(), nil, nil, "repeated")
+				Info(context.Background(), nil, nil, "repeated")
+
+				if err := Close(); err != nil {
+					t.Fatalf("Close() error = %v", err)
+				}
+				// read without Sync, everything is written once Close returns
+				out, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+				if len(lines) != 502 {
+					t.Fatalf("%d logs written on Close, want 502", len(lines))
+				}
+				if !strings.Contains(lines[501], "last message repeated 1 times") {
+					t.Errorf("last log = %s, want the pending repeat", lines[501])
+				}
+
+				if err := Close(); err != nil {
+					t.Errorf("second Close() error = %v, want nil", err)
+				}
+				if err := Sync(); err != nil {
+					t.Errorf("Sync() after Close error = %v, want nil", err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Command logdecrypt restores values encrypted by `log.MaskEncrypt` in a log file.
+//
+// usage:
+//
+//	logdecrypt -key <id>=<hex key> [-key <id>=<hex key> ...] [file]
+//
+// the log is read from stdin when no file is given, and written to stdout.
+// keys may also be given by LOG_DECRYPT_KEYS environment variable, e.g. `2024-01=<hex key>,2024-02=<hex key>`
+// to keep them out of shell history.
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/rizanw/go-log"
+)
+
+type keyFlags map[string][]byte
+
+func (k keyFlags) String() string {
+	ids := make([]string, 0, len(k))
+	for id := range k {
+		ids = append(ids, id)
+	}
+	return strings.Join(ids, ",")
+}
+
+func (k keyFlags) Set(value string) error {
+	id, hexKey, ok := strings.Cut(strings.TrimSpace(value), "=")
+	if !ok || id == "" {
+		return fmt.Errorf("invalid key %q, expected <id>=<hex key>", value)
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return fmt.Errorf("invalid key %q: %w", id, err)
+	}
+	k[id] = key
+	return nil
+}
+
+func main() {
+	keys := make(keyFlags)
+	flag.Var(keys, "key", "decryption key as <id>=<hex key>, repeat it for every key id in the log")
+	flag.Parse()
+
+	if env := os.Getenv("LOG_DECRYPT_KEYS"); env != "" {
+		for _, value := range strings.Split(env, ",") {
+			if err := keys.Set(value); err != nil {
+				exit(err)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		exit(fmt.Errorf("no decryption key, set -key or LOG_DECRYPT_KEYS"))
+	}
+
+	provider, err := log.NewStaticKeyProvider("", keys)
+	if err != nil {
+		exit(err)
+	}
+
+	var input io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		file, err := os.Open(flag.Arg(0))
+		if err != nil {
+			exit(err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	if err = log.DecryptLog(provider, input, os.Stdout); err != nil {
+		exit(err)
+	}
+}
+
+func exit(err error) {
+	fmt.Fprintln(os.Stderr, "logdecrypt:", err)
+	os.Exit(1)
+}
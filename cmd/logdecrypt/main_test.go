@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeyFlagsSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		id      string
+		key     []byte
+		wantErr bool
+	}{
+		{value: "2024-01=000102", id: "2024-01", key: []byte{0, 1, 2}},
+		{value: " 2024-02=ff ", id: "2024-02", key: []byte{0xff}},
+		{value: "2024-01", wantErr: true},
+		{value: "=000102", wantErr: true},
+		{value: "2024-01=zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		keys := make(keyFlags)
+		err := keys.Set(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !bytes.Equal(keys[tt.id], tt.key) {
+			t.Errorf("Set(%q) key of %q = %x, want %x", tt.value, tt.id, keys[tt.id], tt.key)
+		}
+	}
+}
@@ -10,6 +10,7 @@ const (
 	KeyCtxRequestID = "request_id"
 	KeyCtxUserInfo  = "user_info"
 	KeyCtxSource    = "source"
+	KeyCtxLevel     = "log_level"
 )
 
 // SetCtxRequestID generates & sets request_id to context
@@ -68,3 +69,19 @@ func GetCtxSource(ctx context.Context) interface{} {
 	}
 	return nil
 }
+
+// SetCtxLevel sets minimum log level of the context, it overrides the global level
+// for logs of the context only, e.g. debug logs of a single request
+func SetCtxLevel(ctx context.Context, level Level) context.Context {
+	return context.WithValue(ctx, KeyCtxLevel, level)
+}
+
+// GetCtxLevel returns minimum log level of the context, false when it is not set
+func GetCtxLevel(ctx context.Context) (Level, bool) {
+	if ctx == nil {
+		return DebugLevel, false
+	}
+
+	level, ok := ctx.Value(KeyCtxLevel).(Level)
+	return level, ok
+}
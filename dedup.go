@@ -0,0 +1,55 @@
+package log
+
+import (
+	"fmt"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// dedup reports whether the log passes deduplication of the active logger,
+// repeat of the previous log is written first when this log is a different one
+func (l *activeLogger) dedup(level Level, field logger.Field, err error, message string, args ...interface{}) bool {
+	if l.deduper == nil {
+		return true
+	}
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+
+	ok, repeat := l.deduper.Check(level, field, err, message)
+	l.writeRepeat(repeat)
+	return ok
+}
+
+// flushExpiredRepeat writes repeat of the previous log once its window is over
+func (l *activeLogger) flushExpiredRepeat() {
+	l.writeRepeat(l.deduper.Expired())
+}
+
+// flushRepeat writes repeat of the previous log, e.g. before the logger is closed
+func (l *activeLogger) flushRepeat() {
+	l.writeRepeat(l.deduper.Flush())
+}
+
+func (l *activeLogger) writeRepeat(repeat *logger.Repeat) {
+	if repeat == nil {
+		return
+	}
+
+	field := repeat.Field
+	field.Metadata = repeat.Metadata()
+	message := fmt.Sprintf("[log] last message repeated %d times", repeat.Count)
+
+	switch repeat.Level {
+	case TraceLevel:
+		l.logger.Trace(field, nil, message)
+	case DebugLevel:
+		l.logger.Debug(field, nil, message)
+	case InfoLevel:
+		l.logger.Info(field, nil, message)
+	case WarnLevel:
+		l.logger.Warn(field, nil, message)
+	default:
+		l.logger.Error(field, nil, message)
+	}
+}
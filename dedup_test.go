@@ -0,0 +1,67 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDedupRepeatBeforeDifferentLog(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dedup.log")
+			if err := SetConfig(&Config{Engine: engine, FilePath: path, UseJSON: true, DedupWindow: time.Minute}); err != nil {
+				t.Fatal(err)
+			}
+			defer SetConfig(nil)
+
+			ctx := SetCtxRequestID(context.Background(), "req-1")
+			for i := 0; i < 4; i++ {
+				Warn(ctx, nil, KV{"attempt": "same"}, "connection refused")
+			}
+			Info(ctx, nil, nil, "connected")
+
+			lines := readLines(t, path)
+			if len(lines) != 3 {
+				t.Fatalf("want 3 logs, got %v", lines)
+			}
+			for i, want := range []string{"connection refused", "[log] last message repeated 3 times", "connected"} {
+				if lines[i]["message"] != want {
+					t.Errorf("log %d = %v, want %q", i, lines[i]["message"], want)
+				}
+			}
+
+			repeat := lines[1]
+			if repeat["level"] != "warn" || repeat["request_id"] != "req-1" {
+				t.Errorf("repeat log = %v, want the level & fields of the repeated log", repeat)
+			}
+			metadata, _ := repeat["metadata"].(map[string]interface{})
+			if metadata["repeated"] != float64(3) || metadata["repeated_message"] != "connection refused" {
+				t.Errorf("repeat metadata = %v", metadata)
+			}
+		})
+	}
+}
+
+func TestDedupRepeatOnClose(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dedup.log")
+			if err := SetConfig(&Config{Engine: engine, FilePath: path, UseJSON: true, DedupWindow: time.Minute}); err != nil {
+				t.Fatal(err)
+			}
+
+			Info(context.Background(), nil, nil, "same")
+			Info(context.Background(), nil, nil, "same")
+			if err := SetConfig(nil); err != nil {
+				t.Fatal(err)
+			}
+
+			lines := readLines(t, path)
+			if len(lines) != 2 || lines[1]["message"] != "[log] last message repeated 1 times" {
+				t.Errorf("want the repeat written on close, got %v", lines)
+			}
+		})
+	}
+}
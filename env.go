@@ -0,0 +1,174 @@
+package log
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// ConfigFromEnv builds config from `LOG_*` environment variables, unset variables keep their default,
+// e.g. `LOG_LEVEL=warn`, `LOG_ENGINE=zap` or `LOG_NAMED_LEVELS=db=warn,http=info`,
+// see README for every variable. It returns every invalid value at once as an error.
+// note: function options (e.g. StackMarshaller, MaskStrategies) & RedactPatterns can only be set in code
+func ConfigFromEnv() (*Config, error) {
+	var (
+		config Config
+		env    envReader
+	)
+
+	env.string("LOG_APP_NAME", &config.AppName)
+	env.string("LOG_ENVIRONMENT", &config.Environment)
+	env.text("LOG_ENGINE", &config.Engine)
+	env.text("LOG_LEVEL", &config.Level)
+	env.namedLevels("LOG_NAMED_LEVELS", &config.NamedLevels)
+	env.string("LOG_TIME_FORMAT", &config.TimeFormat)
+	env.bool("LOG_WITH_CALLER", &config.WithCaller)
+	env.int("LOG_CALLER_SKIP", &config.CallerSkip)
+	env.bool("LOG_WITH_STACK", &config.WithStack)
+	if _, ok := os.LookupEnv("LOG_STACK_LEVEL"); ok {
+		config.StackLevel = new(Level)
+		env.text("LOG_STACK_LEVEL", config.StackLevel)
+	}
+
+	env.list("LOG_MASK_SENSITIVE_DATA", &config.MaskSensitiveData)
+	var detectors []string
+	env.list("LOG_REDACT_DETECTORS", &detectors)
+	for _, detector := range detectors {
+		config.RedactDetectors = append(config.RedactDetectors, RedactDetector(detector))
+	}
+	if _, err := logger.NewRedactor(config.RedactDetectors, nil); err != nil {
+		env.parse("LOG_REDACT_DETECTORS", strings.Join(detectors, ","), err)
+	}
+
+	env.bool("LOG_USE_JSON", &config.UseJSON)
+	env.bool("LOG_USE_COLOR", &config.UseColor)
+	env.bool("LOG_USE_MULTI_WRITERS", &config.UseMultiWriters)
+
+	env.string("LOG_FILE_PATH", &config.FilePath)
+	env.int("LOG_FILE_MAX_SIZE", &config.FileMaxSize)
+	envOneOf(&env, "LOG_FILE_ROTATE_EVERY", &config.FileRotateEvery, RotateHourly, RotateDaily)
+	env.int("LOG_FILE_MAX_BACKUPS", &config.FileMaxBackups)
+	env.int("LOG_FILE_MAX_AGE", &config.FileMaxAge)
+	env.bool("LOG_FILE_COMPRESS", &config.FileCompress)
+	env.bool("LOG_FILE_REOPEN_ON_SIGHUP", &config.FileReopenOnSIGHUP)
+
+	env.bool("LOG_USE_ASYNC", &config.UseAsync)
+	env.int("LOG_ASYNC_QUEUE_SIZE", &config.AsyncQueueSize)
+	envOneOf(&env, "LOG_ASYNC_OVERFLOW", &config.AsyncOverflow,
+		OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel)
+	env.text("LOG_ASYNC_DROP_LEVEL", &config.AsyncDropLevel)
+
+	env.int("LOG_SAMPLE_FIRST", &config.SampleFirst)
+	env.int("LOG_SAMPLE_THEREAFTER", &config.SampleThereafter)
+	env.duration("LOG_SAMPLE_INTERVAL", &config.SampleInterval)
+	env.float("LOG_RATE_LIMIT", &config.RateLimit)
+	env.int("LOG_RATE_BURST", &config.RateBurst)
+	env.duration("LOG_SAMPLE_SUMMARY_INTERVAL", &config.SampleSummaryInterval)
+	env.duration("LOG_DEDUP_WINDOW", &config.DedupWindow)
+
+	env.bool("LOG_DEBUG_ON_ERROR", &config.DebugOnError)
+	env.int("LOG_DEBUG_ON_ERROR_BUFFER_SIZE", &config.DebugOnErrorBufferSize)
+	env.int("LOG_DEBUG_ON_ERROR_MAX_REQUESTS", &config.DebugOnErrorMaxRequests)
+
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// envReader reads environment variables into config fields, collecting every invalid value
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) parse(key, value string, err error) {
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("invalid %s=%q: %w", key, value, err))
+	}
+}
+
+func (r *envReader) string(key string, dst *string) {
+	if value, ok := os.LookupEnv(key); ok {
+		*dst = value
+	}
+}
+
+func (r *envReader) text(key string, dst encoding.TextUnmarshaler) {
+	if value, ok := os.LookupEnv(key); ok {
+		r.parse(key, value, dst.UnmarshalText([]byte(value)))
+	}
+}
+
+func (r *envReader) bool(key string, dst *bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		var err error
+		*dst, err = strconv.ParseBool(value)
+		r.parse(key, value, err)
+	}
+}
+
+func (r *envReader) int(key string, dst *int) {
+	if value, ok := os.LookupEnv(key); ok {
+		var err error
+		*dst, err = strconv.Atoi(value)
+		r.parse(key, value, err)
+	}
+}
+
+func (r *envReader) float(key string, dst *float64) {
+	if value, ok := os.LookupEnv(key); ok {
+		var err error
+		*dst, err = strconv.ParseFloat(value, 64)
+		r.parse(key, value, err)
+	}
+}
+
+func (r *envReader) duration(key string, dst *time.Duration) {
+	if value, ok := os.LookupEnv(key); ok {
+		var err error
+		*dst, err = time.ParseDuration(value)
+		r.parse(key, value, err)
+	}
+}
+
+// list reads comma separated values, e.g. `password,*_token`
+func (r *envReader) list(key string, dst *[]string) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*dst = append(*dst, item)
+		}
+	}
+}
+
+func (r *envReader) namedLevels(key string, dst *map[string]Level) {
+	if value, ok := os.LookupEnv(key); ok {
+		var err error
+		*dst, err = ParseNamedLevels(value)
+		r.parse(key, value, err)
+	}
+}
+
+// envOneOf reads the value which must be one of the options (case insensitive)
+func envOneOf[T ~string](r *envReader, key string, dst *T, options ...T) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	for _, option := range options {
+		if strings.EqualFold(value, string(option)) {
+			*dst = option
+			return
+		}
+	}
+	r.parse(key, value, fmt.Errorf("must be one of %v", options))
+}
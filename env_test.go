@@ -0,0 +1,117 @@
+package log
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	for key, value := range map[string]string{
+		"LOG_APP_NAME":                "go-app",
+		"LOG_ENGINE":                  "zap",
+		"LOG_LEVEL":                   "warn",
+		"LOG_NAMED_LEVELS":            "db=error, http=debug",
+		"LOG_WITH_CALLER":             "true",
+		"LOG_CALLER_SKIP":             "2",
+		"LOG_STACK_LEVEL":             "error",
+		"LOG_MASK_SENSITIVE_DATA":     " password, ,*_token,",
+		"LOG_REDACT_DETECTORS":        "bearer,email",
+		"LOG_FILE_ROTATE_EVERY":       "DAILY",
+		"LOG_ASYNC_OVERFLOW":          "drop_oldest",
+		"LOG_RATE_LIMIT":              "50.5",
+		"LOG_SAMPLE_SUMMARY_INTERVAL": "1m30s",
+	} {
+		t.Setenv(key, value)
+	}
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.AppName != "go-app" || config.Engine != Zap || config.Level != WarnLevel {
+		t.Errorf("app, engine & level = %s, %s, %s", config.AppName, config.Engine, config.Level)
+	}
+	if want := map[string]Level{"db": ErrorLevel, "http": DebugLevel}; !reflect.DeepEqual(config.NamedLevels, want) {
+		t.Errorf("NamedLevels = %v, want %v", config.NamedLevels, want)
+	}
+	if !config.WithCaller || config.CallerSkip != 2 {
+		t.Errorf("WithCaller & CallerSkip = %v, %d", config.WithCaller, config.CallerSkip)
+	}
+	if config.StackLevel == nil || *config.StackLevel != ErrorLevel {
+		t.Errorf("StackLevel = %v, want error", config.StackLevel)
+	}
+	// blank items are skipped
+	if want := []string{"password", "*_token"}; !reflect.DeepEqual(config.MaskSensitiveData, want) {
+		t.Errorf("MaskSensitiveData = %q, want %q", config.MaskSensitiveData, want)
+	}
+	if want := []RedactDetector{"bearer", "email"}; !reflect.DeepEqual(config.RedactDetectors, want) {
+		t.Errorf("RedactDetectors = %v, want %v", config.RedactDetectors, want)
+	}
+	// options are case insensitive
+	if config.FileRotateEvery != RotateDaily || config.AsyncOverflow != OverflowDropOldest {
+		t.Errorf("FileRotateEvery & AsyncOverflow = %s, %s", config.FileRotateEvery, config.AsyncOverflow)
+	}
+	if config.RateLimit != 50.5 || config.SampleSummaryInterval != 90*time.Second {
+		t.Errorf("RateLimit & SampleSummaryInterval = %v, %s", config.RateLimit, config.SampleSummaryInterval)
+	}
+}
+
+func TestConfigFromEnvUnset(t *testing.T) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*config, Config{}) {
+		t.Errorf("ConfigFromEnv() without variables = %+v, want the default config", *config)
+	}
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	invalid := map[string]string{
+		"LOG_ENGINE":            "logrus",
+		"LOG_LEVEL":             "verbose",
+		"LOG_NAMED_LEVELS":      "db",
+		"LOG_WITH_CALLER":       "yes",
+		"LOG_CALLER_SKIP":       "one",
+		"LOG_RATE_LIMIT":        "fast",
+		"LOG_DEDUP_WINDOW":      "5",
+		"LOG_REDACT_DETECTORS":  "bearer,phone",
+		"LOG_FILE_ROTATE_EVERY": "weekly",
+		"LOG_ASYNC_OVERFLOW":    "drop",
+	}
+	for key, value := range invalid {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, value)
+
+			config, err := ConfigFromEnv()
+			if err == nil || config != nil {
+				t.Fatalf("ConfigFromEnv() = %+v, %v, want an error", config, err)
+			}
+			if !strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), value) {
+				t.Errorf("error %q does not name %s=%s", err, key, value)
+			}
+		})
+	}
+
+	t.Run("all", func(t *testing.T) {
+		for key, value := range invalid {
+			t.Setenv(key, value)
+		}
+		t.Setenv("LOG_APP_NAME", "go-app")
+
+		_, err := ConfigFromEnv()
+		var joined interface{ Unwrap() []error }
+		if !errors.As(err, &joined) || len(joined.Unwrap()) != len(invalid) {
+			t.Fatalf("ConfigFromEnv() = %v, want %d joined errors", err, len(invalid))
+		}
+		for key := range invalid {
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("error %q misses %s", err, key)
+			}
+		}
+	})
+}
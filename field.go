@@ -13,7 +13,20 @@ func buildFields(ctx context.Context, metadata KV) logger.Field {
 
 	if ctx != nil {
 		fields.RequestID = GetCtxRequestID(ctx)
-		fields.Source = GetCtxSource(ctx)
+
+		if traceParent, ok := GetCtxTraceParent(ctx); ok {
+			fields.TraceID = traceParent.TraceID
+			fields.SpanID = traceParent.SpanID
+			fields.TraceFlags = traceParent.TraceFlags
+		}
+
+		source := GetCtxSource(ctx)
+		if src, ok := source.(KV); ok {
+			// assert alias into origin type
+			var m map[string]interface{} = src
+			source = m
+		}
+		fields.Source = source
 
 		userInfo := GetCtxUserInfo(ctx)
 		if ui, ok := userInfo.(KV); ok {
@@ -22,6 +35,10 @@ func buildFields(ctx context.Context, metadata KV) logger.Field {
 			userInfo = m
 		}
 		fields.UserInfo = userInfo
+
+		if level, ok := GetCtxLevel(ctx); ok {
+			fields.MinLevel = &level
+		}
 	}
 
 	if len(metadata) > 0 {
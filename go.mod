@@ -1,17 +1,19 @@
 module github.com/rizanw/go-log
 
-go 1.20
+go 1.21
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.33.0
+	go.opentelemetry.io/otel/trace v1.28.0
 	go.uber.org/zap v1.27.0
 )
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
+	go.opentelemetry.io/otel v1.28.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
-	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
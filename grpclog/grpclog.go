@@ -0,0 +1,264 @@
+package grpclog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/rizanw/go-log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultRequestIDKey is gRPC metadata key carrying request id
+const DefaultRequestIDKey = "x-request-id"
+
+// Config for gRPC interceptors
+type Config struct {
+	// RequestIDKey is metadata key to propagate request id (default: x-request-id)
+	RequestIDKey string
+
+	// LogPayload is a toggle to print request & response messages (default: false)
+	// note: the messages are masked by `log.Config.MaskSensitiveData`
+	LogPayload bool
+}
+
+func (c Config) requestIDKey() string {
+	if c.RequestIDKey == "" {
+		return DefaultRequestIDKey
+	}
+	return c.RequestIDKey
+}
+
+// UnaryServerInterceptor reads request id from incoming metadata (or generates one),
+// echoes it in response header then writes a log for every call
+func UnaryServerInterceptor(config Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = config.serverContext(ctx)
+		defer log.DiscardBufferedLogs(ctx)
+
+		resp, err := handler(ctx, req)
+
+		kv := serverPeer(ctx)
+		if config.LogPayload {
+			kv["request"] = payload(req)
+			if err == nil {
+				kv["response"] = payload(resp)
+			}
+		}
+		logCall(ctx, "[gRPC]", info.FullMethod, start, err, kv)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor reads request id from incoming metadata (or generates one),
+// echoes it in response header then writes a log for every stream
+func StreamServerInterceptor(config Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := config.serverContext(stream.Context())
+		defer log.DiscardBufferedLogs(ctx)
+
+		err := handler(srv, &serverStream{ServerStream: stream, ctx: ctx, config: config})
+
+		logCall(ctx, "[gRPC]", info.FullMethod, start, err, serverPeer(ctx))
+		return err
+	}
+}
+
+// UnaryClientInterceptor propagates request id of the context into outgoing metadata
+// then writes a log for every call
+func UnaryClientInterceptor(config Config) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx = config.clientContext(ctx)
+
+		// peer of outgoing call is only known through the call option
+		var p peer.Peer
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&p))...)
+
+		kv := log.KV{"target": cc.Target()}
+		if p.Addr != nil {
+			kv["peer"] = p.Addr.String()
+		}
+		if config.LogPayload {
+			kv["request"] = payload(req)
+			if err == nil {
+				kv["response"] = payload(reply)
+			}
+		}
+		logCall(ctx, "[gRPC][Client]", method, start, err, kv)
+		return err
+	}
+}
+
+// StreamClientInterceptor propagates request id of the context into outgoing metadata
+// then writes a log once the stream is created
+func StreamClientInterceptor(config Config) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx = config.clientContext(ctx)
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+
+		kv := log.KV{"target": cc.Target()}
+		if stream != nil {
+			// the stream context carries peer of the established transport
+			if p, ok := peer.FromContext(stream.Context()); ok && p.Addr != nil {
+				kv["peer"] = p.Addr.String()
+			}
+		}
+		logCall(ctx, "[gRPC][Client][Stream]", method, start, err, kv)
+		if err != nil {
+			return nil, err
+		}
+		return &clientStream{ClientStream: stream, ctx: ctx, config: config}, nil
+	}
+}
+
+// CodeToLevel returns log level of gRPC status code
+func CodeToLevel(code codes.Code) log.Level {
+	switch code {
+	case codes.OK:
+		return log.InfoLevel
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return log.WarnLevel
+	default:
+		return log.ErrorLevel
+	}
+}
+
+// serverContext sets request id & trace parent of incoming metadata into the context
+func (c Config) serverContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(c.requestIDKey()); len(values) > 0 && values[0] != "" {
+		ctx = log.SetCtxRequestID(ctx, values[0])
+	} else {
+		ctx = log.SetCtxRequestID(ctx)
+	}
+	if values := md.Get(log.HeaderTraceParent); len(values) > 0 {
+		if traceParent, err := log.ParseTraceParent(values[0]); err == nil {
+			ctx = log.SetCtxTraceParent(ctx, traceParent)
+		}
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(c.requestIDKey(), log.GetCtxRequestID(ctx)))
+	return ctx
+}
+
+// clientContext puts request id & trace parent of the context into outgoing metadata
+func (c Config) clientContext(ctx context.Context) context.Context {
+	if log.GetCtxRequestID(ctx) == "" {
+		ctx = log.SetCtxRequestID(ctx)
+	}
+
+	pairs := []string{c.requestIDKey(), log.GetCtxRequestID(ctx)}
+	if traceParent, ok := log.GetCtxTraceParent(ctx); ok {
+		pairs = append(pairs, log.HeaderTraceParent, traceParent.String())
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// serverPeer returns log fields with peer of the incoming call
+func serverPeer(ctx context.Context) log.KV {
+	kv := log.KV{}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		kv["peer"] = p.Addr.String()
+	}
+	return kv
+}
+
+func logCall(ctx context.Context, prefix, method string, start time.Time, err error, kv log.KV) {
+	code := status.Code(err)
+	kv["grpc_method"] = method
+	kv["grpc_code"] = code.String()
+	kv["latency_ms"] = float64(time.Since(start).Microseconds()) / 1000
+
+	message := fmt.Sprintf("%s %s %s", prefix, method, code.String())
+	switch CodeToLevel(code) {
+	case log.ErrorLevel:
+		log.Error(ctx, err, kv, message)
+	case log.WarnLevel:
+		log.Warn(ctx, err, kv, message)
+	default:
+		log.Info(ctx, err, kv, message)
+	}
+}
+
+// payload converts message into map, so it can be masked like other metadata
+func payload(message interface{}) interface{} {
+	protoMessage, ok := message.(proto.Message)
+	if !ok {
+		return message
+	}
+
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(protoMessage)
+	if err != nil {
+		return message
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return message
+	}
+	return m
+}
+
+// serverStream carries request id context & logs stream messages
+type serverStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	config Config
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.config.LogPayload {
+		log.Debug(s.ctx, nil, log.KV{"request": payload(m)}, "[gRPC][Stream] message received")
+	}
+	return err
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil && s.config.LogPayload {
+		log.Debug(s.ctx, nil, log.KV{"response": payload(m)}, "[gRPC][Stream] message sent")
+	}
+	return err
+}
+
+// clientStream logs stream messages
+type clientStream struct {
+	grpc.ClientStream
+	ctx    context.Context
+	config Config
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil && s.config.LogPayload {
+		log.Debug(s.ctx, nil, log.KV{"request": payload(m)}, "[gRPC][Client][Stream] message sent")
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil && s.config.LogPayload {
+		log.Debug(s.ctx, nil, log.KV{"response": payload(m)}, "[gRPC][Client][Stream] message received")
+	}
+	return err
+}
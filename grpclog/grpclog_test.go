@@ -0,0 +1,290 @@
+package grpclog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/rizanw/go-log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	unaryMethod  = "/test.Echo/Unary"
+	streamMethod = "/test.Echo/Stream"
+)
+
+// echoService echoes messages, the value `fail` returns an internal error
+var echoService = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Unary",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if req.(*wrapperspb.StringValue).GetValue() == "fail" {
+					return nil, status.Error(codes.Internal, "echo failed")
+				}
+				return req, nil
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: unaryMethod}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Stream",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			for {
+				in := new(wrapperspb.StringValue)
+				if err := stream.RecvMsg(in); errors.Is(err, io.EOF) {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if err := stream.SendMsg(in); err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+// setup starts echo server with the interceptors over bufconn, it returns client connection
+// & a func to get logs written so far
+func setup(t *testing.T, config Config) (*grpc.ClientConn, func() []map[string]interface{}) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "grpc.log")
+	if err := log.SetConfig(&log.Config{Engine: log.Zap, FilePath: path, UseJSON: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(config)),
+		grpc.StreamInterceptor(StreamServerInterceptor(config)),
+	)
+	server.RegisterService(&echoService, struct{}{})
+	go func() { _ = server.Serve(listener) }()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(config)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(config)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		server.Stop()
+		_ = log.SetConfig(nil)
+	})
+
+	return conn, func() []map[string]interface{} {
+		if err := log.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		return readLogs(t, path)
+	}
+}
+
+func readLogs(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var logs []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid log line %q: %v", scanner.Text(), err)
+		}
+		logs = append(logs, line)
+	}
+	return logs
+}
+
+func findLog(t *testing.T, logs []map[string]interface{}, message string) map[string]interface{} {
+	t.Helper()
+
+	for _, line := range logs {
+		if line["message"] == message {
+			return line
+		}
+	}
+	t.Fatalf("no log %q in %v", message, logs)
+	return nil
+}
+
+func metadataOf(line map[string]interface{}) map[string]interface{} {
+	m, _ := line["metadata"].(map[string]interface{})
+	return m
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		code      codes.Code
+		level     string
+		wantReply bool
+	}{
+		{name: "ok", value: "hello", code: codes.OK, level: "info", wantReply: true},
+		{name: "error", value: "fail", code: codes.Internal, level: "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, logs := setup(t, Config{LogPayload: true})
+
+			var (
+				ctx    = log.SetCtxRequestID(context.Background(), "req-"+tt.name)
+				reply  = new(wrapperspb.StringValue)
+				header metadata.MD
+			)
+			err := conn.Invoke(ctx, unaryMethod, wrapperspb.String(tt.value), reply, grpc.Header(&header))
+			if status.Code(err) != tt.code {
+				t.Fatalf("Invoke() code = %s, want %s", status.Code(err), tt.code)
+			}
+			if got := header.Get(DefaultRequestIDKey); len(got) != 1 || got[0] != "req-"+tt.name {
+				t.Errorf("response header request id = %v, want %q", got, "req-"+tt.name)
+			}
+
+			all := logs()
+			for _, prefix := range []string{"[gRPC]", "[gRPC][Client]"} {
+				line := findLog(t, all, prefix+" "+unaryMethod+" "+tt.code.String())
+				md := metadataOf(line)
+
+				if line["level"] != tt.level {
+					t.Errorf("%s level = %v, want %s", prefix, line["level"], tt.level)
+				}
+				if line["request_id"] != "req-"+tt.name {
+					t.Errorf("%s request_id = %v, want %q", prefix, line["request_id"], "req-"+tt.name)
+				}
+				if md["peer"] != "bufconn" {
+					t.Errorf("%s peer = %v, want bufconn", prefix, md["peer"])
+				}
+				if md["grpc_code"] != tt.code.String() {
+					t.Errorf("%s grpc_code = %v, want %s", prefix, md["grpc_code"], tt.code)
+				}
+				if request, _ := md["request"].(map[string]interface{}); request["value"] != tt.value {
+					t.Errorf("%s request = %v, want value %q", prefix, md["request"], tt.value)
+				}
+				if _, ok := md["response"]; ok != tt.wantReply {
+					t.Errorf("%s has response = %v, want %v", prefix, ok, tt.wantReply)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	conn, logs := setup(t, Config{LogPayload: true})
+
+	ctx := log.SetCtxRequestID(context.Background(), "req-stream")
+	stream, err := conn.NewStream(ctx, &echoService.Streams[0], streamMethod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"one", "two"} {
+		if err := stream.SendMsg(wrapperspb.String(value)); err != nil {
+			t.Fatal(err)
+		}
+		reply := new(wrapperspb.StringValue)
+		if err := stream.RecvMsg(reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.GetValue() != value {
+			t.Fatalf("reply = %q, want %q", reply.GetValue(), value)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.RecvMsg(new(wrapperspb.StringValue)); !errors.Is(err, io.EOF) {
+		t.Fatalf("RecvMsg() error = %v, want EOF", err)
+	}
+
+	all := logs()
+	for _, message := range []string{
+		"[gRPC] " + streamMethod + " OK",
+		"[gRPC][Client][Stream] " + streamMethod + " OK",
+	} {
+		line := findLog(t, all, message)
+		if line["request_id"] != "req-stream" {
+			t.Errorf("%s request_id = %v, want req-stream", message, line["request_id"])
+		}
+		if md := metadataOf(line); md["peer"] != "bufconn" {
+			t.Errorf("%s peer = %v, want bufconn", message, md["peer"])
+		}
+	}
+
+	var payloads int
+	for _, line := range all {
+		switch line["message"] {
+		case "[gRPC][Stream] message received", "[gRPC][Stream] message sent",
+			"[gRPC][Client][Stream] message sent", "[gRPC][Client][Stream] message received":
+			payloads++
+			if line["request_id"] != "req-stream" {
+				t.Errorf("%v request_id = %v, want req-stream", line["message"], line["request_id"])
+			}
+		}
+	}
+	if payloads != 8 {
+		t.Errorf("stream message logs = %d, want 8", payloads)
+	}
+}
+
+func TestCodeToLevel(t *testing.T) {
+	tests := map[codes.Code]log.Level{
+		codes.OK:                 log.InfoLevel,
+		codes.Canceled:           log.WarnLevel,
+		codes.InvalidArgument:    log.WarnLevel,
+		codes.NotFound:           log.WarnLevel,
+		codes.AlreadyExists:      log.WarnLevel,
+		codes.PermissionDenied:   log.WarnLevel,
+		codes.Unauthenticated:    log.WarnLevel,
+		codes.ResourceExhausted:  log.WarnLevel,
+		codes.FailedPrecondition: log.WarnLevel,
+		codes.Aborted:            log.WarnLevel,
+		codes.OutOfRange:         log.WarnLevel,
+		codes.Unknown:            log.ErrorLevel,
+		codes.DeadlineExceeded:   log.ErrorLevel,
+		codes.Unimplemented:      log.ErrorLevel,
+		codes.Internal:           log.ErrorLevel,
+		codes.Unavailable:        log.ErrorLevel,
+		codes.DataLoss:           log.ErrorLevel,
+	}
+
+	for code, want := range tests {
+		if got := CodeToLevel(code); got != want {
+			t.Errorf("CodeToLevel(%s) = %s, want %s", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package httplog
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/rizanw/go-log"
+)
+
+const (
+	// DefaultRequestIDHeader is header name carrying request id
+	DefaultRequestIDHeader = "X-Request-ID"
+
+	// DefaultLevelHeader is header name carrying signed minimum log level of the request
+	DefaultLevelHeader = "X-Debug-Log"
+
+	// maxRequestIDLength is maximum length of incoming request id
+	maxRequestIDLength = 128
+)
+
+// Config for HTTP middleware
+type Config struct {
+	// RequestIDHeader is header to read incoming request id and to echo it in the response (default: X-Request-ID)
+	RequestIDHeader string
+
+	// Paths is allowlist of path prefixes to be access logged (default: every path)
+	// note: request id is still assigned for every path
+	Paths []string
+
+	// Headers is allowlist of request headers to be printed in access log (default: no header)
+	// note: add sensitive headers (e.g. authorization) into `log.Config.MaskSensitiveData` to mask them
+	Headers []string
+
+	// LevelHeader is header to set minimum log level of the request (default: X-Debug-Log),
+	// its value must be signed by SignLevel, see LevelHeaderSecret
+	LevelHeader string
+
+	// LevelHeaderSecret is HMAC secret to verify LevelHeader (default: empty, the header is ignored)
+	LevelHeaderSecret []byte
+}
+
+// Middleware assigns request id into the context & the response then writes access log of every request
+func Middleware(config Config) func(http.Handler) http.Handler {
+	if config.RequestIDHeader == "" {
+		config.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if config.LevelHeader == "" {
+		config.LevelHeader = DefaultLevelHeader
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				start = time.Now()
+				ctx   = r.Context()
+			)
+
+			if requestID := r.Header.Get(config.RequestIDHeader); isValidRequestID(requestID) {
+				ctx = log.SetCtxRequestID(ctx, requestID)
+			} else {
+				ctx = log.SetCtxRequestID(ctx)
+			}
+			if traceCtx, err := log.ExtractTraceParent(ctx, r.Header); err == nil {
+				ctx = traceCtx
+			}
+			if level, ok := config.level(r.Header, start); ok {
+				ctx = log.SetCtxLevel(ctx, level)
+			}
+			w.Header().Set(config.RequestIDHeader, log.GetCtxRequestID(ctx))
+			defer log.DiscardBufferedLogs(ctx)
+
+			recorder := &responseRecorder{ResponseWriter: w}
+			r = r.WithContext(ctx)
+			next.ServeHTTP(recorder, r)
+
+			if !config.isPathAllowed(r.URL.Path) {
+				return
+			}
+
+			metadata := log.KV{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"status":      recorder.statusCode(),
+				"bytes":       recorder.bytes,
+				"latency_ms":  float64(time.Since(start).Microseconds()) / 1000,
+				"remote_addr": r.RemoteAddr,
+				"user_agent":  r.UserAgent(),
+			}
+			if headers := config.headers(r.Header); len(headers) > 0 {
+				metadata["headers"] = headers
+			}
+
+			message := fmt.Sprintf("[HTTP] %s %s %d", r.Method, r.URL.Path, recorder.statusCode())
+			switch statusLevel(recorder.statusCode()) {
+			case log.ErrorLevel:
+				log.Error(ctx, nil, metadata, message)
+			case log.WarnLevel:
+				log.Warn(ctx, nil, metadata, message)
+			default:
+				log.Info(ctx, nil, metadata, message)
+			}
+		})
+	}
+}
+
+func (c Config) isPathAllowed(path string) bool {
+	if len(c.Paths) == 0 {
+		return true
+	}
+	for _, prefix := range c.Paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// level returns minimum log level of the request from the signed level header
+func (c Config) level(header http.Header, now time.Time) (log.Level, bool) {
+	value := header.Get(c.LevelHeader)
+	if len(c.LevelHeaderSecret) == 0 || value == "" {
+		return log.DebugLevel, false
+	}
+	return verifyLevel(c.LevelHeaderSecret, value, now)
+}
+
+// isValidRequestID reports whether incoming request id is safe to be echoed & logged,
+// it must be 1 to maxRequestIDLength of `[A-Za-z0-9._-]`, otherwise a new one is generated
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		switch c := requestID[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// headers returns allowed request headers, keyed by lower case name so they match masking keys
+func (c Config) headers(header http.Header) map[string]interface{} {
+	headers := make(map[string]interface{})
+	for _, name := range c.Headers {
+		if value := header.Get(name); value != "" {
+			headers[strings.ToLower(name)] = value
+		}
+	}
+	return headers
+}
+
+// statusLevel returns log level based on response status code
+func statusLevel(status int) log.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return log.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return log.WarnLevel
+	default:
+		return log.InfoLevel
+	}
+}
+
+// responseRecorder records status code & written bytes of the response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
+
+// Flush supports streaming responses
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the original response writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,51 @@
+package httplog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	log "github.com/rizanw/go-log"
+)
+
+func TestMiddlewareRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "valid", incoming: "req-1.abc_DEF", keep: true},
+		{name: "max length", incoming: strings.Repeat("a", maxRequestIDLength), keep: true},
+		{name: "empty", incoming: ""},
+		{name: "too long", incoming: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "space", incoming: "req 1"},
+		{name: "newline", incoming: "req-1\nforged"},
+		{name: "quote", incoming: `req-1"`},
+		{name: "non ascii", incoming: "réq-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestID string
+			handler := Middleware(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = log.GetCtxRequestID(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(DefaultRequestIDHeader, tt.incoming)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if tt.keep && requestID != tt.incoming {
+				t.Errorf("request id = %q, want %q", requestID, tt.incoming)
+			}
+			if !tt.keep && (requestID == tt.incoming || !isValidRequestID(requestID)) {
+				t.Errorf("request id = %q, want a generated one", requestID)
+			}
+			if echoed := w.Header().Get(DefaultRequestIDHeader); echoed != requestID {
+				t.Errorf("echoed request id = %q, want %q", echoed, requestID)
+			}
+		})
+	}
+}
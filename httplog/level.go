@@ -0,0 +1,55 @@
+package httplog
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/rizanw/go-log"
+)
+
+// SignLevel returns value of the level header which sets minimum log level of a request until expiresAt,
+// e.g. `debug:1700000000:<signature>`, the secret must be the same as Config.LevelHeaderSecret
+func SignLevel(secret []byte, level log.Level, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%s:%d", level, expiresAt.Unix())
+	return payload + ":" + hex.EncodeToString(levelSignature(secret, payload))
+}
+
+// verifyLevel returns the level of signed header value, false when it is malformed, forged or expired
+func verifyLevel(secret []byte, value string, now time.Time) (log.Level, bool) {
+	i := strings.LastIndexByte(value, ':')
+	if i < 0 {
+		return log.DebugLevel, false
+	}
+	payload, signature := value[:i], value[i+1:]
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, levelSignature(secret, payload)) {
+		return log.DebugLevel, false
+	}
+
+	name, expiry, ok := strings.Cut(payload, ":")
+	if !ok {
+		return log.DebugLevel, false
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return log.DebugLevel, false
+	}
+
+	level, err := log.ParseLevel(name)
+	if err != nil {
+		return log.DebugLevel, false
+	}
+	return level, true
+}
+
+func levelSignature(secret []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,127 @@
+package httplog
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/rizanw/go-log"
+)
+
+var testSecret = []byte("level-secret")
+
+func TestVerifyLevel(t *testing.T) {
+	var (
+		now     = time.Unix(1700000000, 0)
+		expires = now.Add(time.Minute)
+		signed  = SignLevel(testSecret, log.DebugLevel, expires)
+		payload = signed[:strings.LastIndexByte(signed, ':')]
+		sig     = signed[strings.LastIndexByte(signed, ':'):]
+	)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		value  string
+		now    time.Time
+		want   log.Level
+		wantOK bool
+	}{
+		{name: "valid", value: signed, now: now, want: log.DebugLevel, wantOK: true},
+		{name: "valid until expiry", value: signed, now: expires, want: log.DebugLevel, wantOK: true},
+		{name: "valid error level", value: SignLevel(testSecret, log.ErrorLevel, expires), now: now, want: log.ErrorLevel, wantOK: true},
+		{name: "expired", value: signed, now: expires.Add(time.Second)},
+		{name: "tampered level", value: strings.Replace(payload, "debug", "trace", 1) + sig, now: now},
+		{name: "tampered expiry", value: "debug:" + "1800000000" + sig, now: now},
+		{name: "other secret", secret: []byte("other"), value: signed, now: now},
+		{name: "signed unknown level", value: signLevelPayload("verbose:1700000060"), now: now},
+		{name: "signed invalid expiry", value: signLevelPayload("debug:tomorrow"), now: now},
+		{name: "signed without expiry", value: signLevelPayload("debug"), now: now},
+		{name: "no signature", value: "debug", now: now},
+		{name: "non hex signature", value: payload + ":zz", now: now},
+		{name: "empty", value: "", now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := tt.secret
+			if secret == nil {
+				secret = testSecret
+			}
+			got, ok := verifyLevel(secret, tt.value, tt.now)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("verifyLevel(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// signLevelPayload signs any payload, to test values SignLevel never generates
+func signLevelPayload(payload string) string {
+	return payload + ":" + hex.EncodeToString(levelSignature(testSecret, payload))
+}
+
+func TestMiddlewareLevelHeader(t *testing.T) {
+	valid := SignLevel(testSecret, log.DebugLevel, time.Now().Add(time.Minute))
+	tests := []struct {
+		name      string
+		level     log.Level
+		secret    []byte
+		header    string
+		wantDebug bool
+		wantInfo  bool
+	}{
+		{name: "lowers the level", level: log.InfoLevel, secret: testSecret, header: valid, wantDebug: true, wantInfo: true},
+		{name: "raises the level", level: log.DebugLevel, secret: testSecret, header: SignLevel(testSecret, log.ErrorLevel, time.Now().Add(time.Minute))},
+		{name: "without header", level: log.InfoLevel, secret: testSecret, wantInfo: true},
+		{name: "invalid header", level: log.InfoLevel, secret: testSecret, header: "debug:9999999999:00", wantInfo: true},
+		{name: "expired header", level: log.InfoLevel, secret: testSecret, header: SignLevel(testSecret, log.DebugLevel, time.Now().Add(-time.Minute)), wantInfo: true},
+		{name: "missing secret disables the header", level: log.InfoLevel, header: valid, wantInfo: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "level.log")
+			if err := log.SetConfig(&log.Config{FilePath: path, Level: tt.level}); err != nil {
+				t.Fatal(err)
+			}
+			defer log.SetConfig(nil)
+
+			handler := Middleware(Config{Paths: []string{"/access"}, LevelHeaderSecret: tt.secret})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				log.Debug(r.Context(), nil, nil, "debug log")
+				log.Info(r.Context(), nil, nil, "info log")
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(DefaultLevelHeader, tt.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			// a request without the header is logged as usual
+			log.Debug(context.Background(), nil, nil, "other request")
+			if err := log.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(out), "debug log"); got != tt.wantDebug {
+				t.Errorf("debug log written = %v, want %v:\n%s", got, tt.wantDebug, out)
+			}
+			if got := strings.Contains(string(out), "info log"); got != tt.wantInfo {
+				t.Errorf("info log written = %v, want %v:\n%s", got, tt.wantInfo, out)
+			}
+			if got, want := strings.Contains(string(out), "other request"), tt.level == log.DebugLevel; got != want {
+				t.Errorf("log of other request written = %v, want %v:\n%s", got, want, out)
+			}
+		})
+	}
+}
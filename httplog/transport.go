@@ -0,0 +1,135 @@
+package httplog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/rizanw/go-log"
+)
+
+// TransportConfig for outgoing HTTP client logging
+type TransportConfig struct {
+	// RequestIDHeader is header to propagate request id of the context (default: X-Request-ID)
+	RequestIDHeader string
+
+	// RequestBodyLimit is maximum bytes of request body to be printed (default: 0, no body)
+	RequestBodyLimit int
+
+	// ResponseBodyLimit is maximum bytes of response body to be printed (default: 0, no body)
+	// note: the body is read up to the limit before the response is returned
+	ResponseBodyLimit int
+}
+
+// Transport is http.RoundTripper which propagates request id & trace parent of the request context
+// into outgoing requests and writes a log for every call
+type Transport struct {
+	base   http.RoundTripper
+	config TransportConfig
+}
+
+// NewTransport wraps base round tripper (default: http.DefaultTransport)
+func NewTransport(base http.RoundTripper, config TransportConfig) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if config.RequestIDHeader == "" {
+		config.RequestIDHeader = DefaultRequestIDHeader
+	}
+
+	return &Transport{
+		base:   base,
+		config: config,
+	}
+}
+
+// RoundTrip sends the request with request id & trace headers then logs the call
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var (
+		start = time.Now()
+		ctx   = r.Context()
+	)
+
+	// round tripper must not modify the given request
+	r = r.Clone(ctx)
+	if requestID := log.GetCtxRequestID(ctx); requestID != "" && r.Header.Get(t.config.RequestIDHeader) == "" {
+		r.Header.Set(t.config.RequestIDHeader, requestID)
+	}
+	if r.Header.Get(log.HeaderTraceParent) == "" {
+		log.InjectTraceParent(ctx, r.Header)
+	}
+
+	metadata := log.KV{
+		"method": r.Method,
+		"url":    redactedURL(r),
+	}
+	if query := queryValues(r); len(query) > 0 {
+		metadata["query"] = query
+	}
+	if t.config.RequestBodyLimit > 0 && r.Body != nil && r.Body != http.NoBody {
+		var body []byte
+		body, r.Body = captureBody(r.Body, t.config.RequestBodyLimit)
+		metadata["request_body"] = string(body)
+	}
+
+	resp, err := t.base.RoundTrip(r)
+	metadata["latency_ms"] = float64(time.Since(start).Microseconds()) / 1000
+
+	if err != nil {
+		log.Error(ctx, err, metadata, fmt.Sprintf("[HTTP][Client] %s %s failed", r.Method, redactedURL(r)))
+		return resp, err
+	}
+
+	metadata["status"] = resp.StatusCode
+	if t.config.ResponseBodyLimit > 0 && resp.Body != nil && resp.Body != http.NoBody {
+		var body []byte
+		body, resp.Body = captureBody(resp.Body, t.config.ResponseBodyLimit)
+		metadata["response_body"] = string(body)
+	}
+
+	message := fmt.Sprintf("[HTTP][Client] %s %s %d", r.Method, redactedURL(r), resp.StatusCode)
+	switch statusLevel(resp.StatusCode) {
+	case log.ErrorLevel:
+		log.Error(ctx, nil, metadata, message)
+	case log.WarnLevel:
+		log.Warn(ctx, nil, metadata, message)
+	default:
+		log.Info(ctx, nil, metadata, message)
+	}
+	return resp, nil
+}
+
+// redactedURL returns request url without user info & query, query is printed separately to be masked
+func redactedURL(r *http.Request) string {
+	u := *r.URL
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// queryValues returns query of the url as map, so its values are masked by MaskSensitiveData keys
+func queryValues(r *http.Request) map[string]interface{} {
+	query := make(map[string]interface{})
+	for key, values := range r.URL.Query() {
+		query[key] = strings.Join(values, ",")
+	}
+	return query
+}
+
+// captureBody reads body up to the limit and returns a body which still yields every byte
+func captureBody(body io.ReadCloser, limit int) ([]byte, io.ReadCloser) {
+	captured, _ := io.ReadAll(io.LimitReader(body, int64(limit)))
+	return captured, readCloser{
+		Reader: io.MultiReader(bytes.NewReader(captured), body),
+		Closer: body,
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,231 @@
+package httplog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/rizanw/go-log"
+)
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// jsonLog sets go-log to write json into a file, it returns function reading the written logs
+func jsonLog(t *testing.T, config log.Config) func() []map[string]interface{} {
+	t.Helper()
+
+	config.FilePath = filepath.Join(t.TempDir(), "transport.log")
+	config.UseJSON = true
+	if err := log.SetConfig(&config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = log.SetConfig(nil) })
+
+	return func() []map[string]interface{} {
+		t.Helper()
+
+		if err := log.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.ReadFile(config.FilePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var logs []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatalf("invalid log line %q: %v", line, err)
+			}
+			logs = append(logs, m)
+		}
+		return logs
+	}
+}
+
+func TestTransportPropagation(t *testing.T) {
+	readLogs := jsonLog(t, log.Config{})
+
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	traceParent := log.TraceParent{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", TraceFlags: "01"}
+	ctx := log.SetCtxTraceParent(log.SetCtxRequestID(context.Background(), "req-1"), traceParent)
+	client := &http.Client{Transport: NewTransport(nil, TransportConfig{})}
+
+	r, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := client.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := received.Get(DefaultRequestIDHeader); got != "req-1" {
+		t.Errorf("request id header = %q, want req-1", got)
+	}
+	if got := received.Get(log.HeaderTraceParent); got != traceParent.String() {
+		t.Errorf("traceparent header = %q, want %q", got, traceParent.String())
+	}
+	if len(r.Header) != 0 {
+		t.Errorf("headers are set into the given request: %v", r.Header)
+	}
+
+	// headers set by the caller are kept
+	r, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	r.Header.Set(DefaultRequestIDHeader, "caller-id")
+	r.Header.Set(log.HeaderTraceParent, "00-11111111111111111111111111111111-1111111111111111-00")
+	resp, err = client.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := received.Get(DefaultRequestIDHeader); got != "caller-id" {
+		t.Errorf("request id header = %q, want the one of the caller", got)
+	}
+	if got := received.Get(log.HeaderTraceParent); !strings.HasPrefix(got, "00-1111") {
+		t.Errorf("traceparent header = %q, want the one of the caller", got)
+	}
+
+	logs := readLogs()
+	if len(logs) != 2 {
+		t.Fatalf("want 2 logs, got %v", logs)
+	}
+	if logs[0]["request_id"] != "req-1" || logs[0]["trace_id"] != traceParent.TraceID {
+		t.Errorf("log is not correlated with the request: %v", logs[0])
+	}
+}
+
+func TestTransportURL(t *testing.T) {
+	readLogs := jsonLog(t, log.Config{MaskSensitiveData: []string{"token"}})
+
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, TransportConfig{})}
+	resp, err := client.Get(server.URL + "/users?token=secret&page=2&page=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if rawQuery != "token=secret&page=2&page=3" {
+		t.Errorf("sent query = %q, want it untouched", rawQuery)
+	}
+
+	logs := readLogs()
+	metadata, _ := logs[0]["metadata"].(map[string]interface{})
+	if metadata["url"] != server.URL+"/users" {
+		t.Errorf("url = %v, want it without query", metadata["url"])
+	}
+	if message, _ := logs[0]["message"].(string); strings.Contains(message, "secret") || strings.Contains(message, "?") {
+		t.Errorf("message = %q, want it without query", message)
+	}
+	query, _ := metadata["query"].(map[string]interface{})
+	if query["token"] != "******" || query["page"] != "2,3" {
+		t.Errorf("query = %v, want token masked & page kept", query)
+	}
+}
+
+func TestTransportBody(t *testing.T) {
+	readLogs := jsonLog(t, log.Config{})
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		_, _ = io.WriteString(w, "response body is long")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil, TransportConfig{RequestBodyLimit: 7, ResponseBodyLimit: 8})}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("request body is long"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received != "request body is long" {
+		t.Errorf("server received %q, want the whole request body", received)
+	}
+	if string(body) != "response body is long" {
+		t.Errorf("client read %q, want the whole response body", body)
+	}
+
+	metadata, _ := readLogs()[0]["metadata"].(map[string]interface{})
+	if metadata["request_body"] != "request" || metadata["response_body"] != "response" {
+		t.Errorf("logged bodies = %q & %q, want them truncated at the limits", metadata["request_body"], metadata["response_body"])
+	}
+}
+
+func TestTransportLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		roundTrip roundTripFunc
+		wantLevel string
+		wantErr   bool
+	}{
+		{name: "ok", roundTrip: statusRoundTrip(http.StatusOK), wantLevel: "info"},
+		{name: "redirect", roundTrip: statusRoundTrip(http.StatusNotModified), wantLevel: "info"},
+		{name: "client error", roundTrip: statusRoundTrip(http.StatusNotFound), wantLevel: "warn"},
+		{name: "server error", roundTrip: statusRoundTrip(http.StatusBadGateway), wantLevel: "error"},
+		{
+			name: "transport error",
+			roundTrip: func(r *http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			},
+			wantLevel: "error",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readLogs := jsonLog(t, log.Config{})
+
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/path", nil)
+			resp, err := NewTransport(tt.roundTrip, TransportConfig{ResponseBodyLimit: 10}).RoundTrip(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			logs := readLogs()
+			if len(logs) != 1 {
+				t.Fatalf("want 1 log, got %v", logs)
+			}
+			if logs[0]["level"] != tt.wantLevel {
+				t.Errorf("level = %v, want %s", logs[0]["level"], tt.wantLevel)
+			}
+			if tt.wantErr && !strings.Contains(logs[0]["error"].(string), "connection refused") {
+				t.Errorf("error = %v, want the transport error", logs[0]["error"])
+			}
+		})
+	}
+}
+
+func statusRoundTrip(status int) roundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: http.NoBody, Request: r}, nil
+	}
+}
@@ -0,0 +1,28 @@
+package log
+
+import (
+	"net/http"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// SetLevel changes minimum log level at runtime without rebuilding the logger
+func SetLevel(level Level) {
+	rlevel.SetLevel(level)
+}
+
+// GetLevel returns current minimum log level
+func GetLevel() Level {
+	return rlevel.Level()
+}
+
+// LevelHandler returns http.Handler to get (GET) & change (PUT) current log level as JSON,
+// e.g. `curl -X PUT -d '{"level":"debug"}' localhost:8080/log/level`
+func LevelHandler() http.Handler {
+	return rlevel
+}
+
+// ParseLevel returns the level of the given case insensitive name, e.g. debug or DEBUG
+func ParseLevel(name string) (Level, error) {
+	return logger.ParseLevel(name)
+}
@@ -0,0 +1,78 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevelOrder(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			var (
+				path       = filepath.Join(t.TempDir(), "level.log")
+				stackLevel = FatalLevel
+			)
+			err := SetConfig(&Config{Engine: engine, Level: FatalLevel, FilePath: path, UseJSON: true, WithStack: true, StackLevel: &stackLevel})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer SetConfig(nil)
+
+			Error(context.Background(), nil, nil, "error below fatal")
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("Panic did not panic")
+					}
+				}()
+				Panic(context.Background(), nil, nil, "panic above fatal")
+			}()
+
+			out := readFile(t, path)
+			if strings.Contains(out, "error below fatal") {
+				t.Errorf("error log is written below fatal level:\n%s", out)
+			}
+			if !strings.Contains(out, "panic above fatal") {
+				t.Errorf("panic log is not written at fatal level:\n%s", out)
+			}
+			if engine == Zap && !strings.Contains(out, `"stacktrace"`) {
+				t.Errorf("panic log has no stack trace at fatal stack level:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "level.log")
+			if err := SetConfig(&Config{Engine: engine, Level: InfoLevel, FilePath: path}); err != nil {
+				t.Fatal(err)
+			}
+			defer SetConfig(nil)
+
+			Debug(context.Background(), nil, nil, "debug before put")
+			w := httptest.NewRecorder()
+			LevelHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`)))
+			if w.Code != http.StatusOK || GetLevel() != DebugLevel {
+				t.Fatalf("PUT status = %d, level = %v, want debug", w.Code, GetLevel())
+			}
+			Debug(context.Background(), nil, nil, "debug after put")
+
+			w = httptest.NewRecorder()
+			LevelHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+			if got := strings.TrimSpace(w.Body.String()); got != `{"level":"debug"}` {
+				t.Errorf("GET body = %s, want the changed level", got)
+			}
+
+			out := readFile(t, path)
+			if strings.Contains(out, "debug before put") || !strings.Contains(out, "debug after put") {
+				t.Errorf("want only the debug log after PUT, got:\n%s", out)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package log
 
 import (
 	"context"
+	"time"
 
 	"github.com/rizanw/go-log/logger"
+	"github.com/rizanw/go-log/logger/writer"
 )
 
 // Config for Log configuration
@@ -20,6 +22,10 @@ type Config struct {
 	// Level is minimum log level to be printed (default: DEBUG)
 	Level Level
 
+	// NamedLevels is minimum log level per logger name of log.Named, e.g. `{"db": log.WarnLevel}`,
+	// a dotted name inherits its closest parent (e.g. `db.pool` inherits `db`), the rest uses Level
+	NamedLevels map[string]Level
+
 	// TimeFormat is for log time format (default: RFC3339)
 	TimeFormat string
 
@@ -38,12 +44,26 @@ type Config struct {
 	// StackMarshaller, function to get and log the stack trace for zerolog (default: `zerolog/pkgerrors`)
 	StackMarshaller func(err error) interface{}
 
-	// MaskSensitiveData is keys of field to be masked
+	// MaskSensitiveData is rules of field keys to be masked, every rule is case-insensitive:
+	// a name (e.g. `password`), a glob pattern (e.g. `*_token`) or a dotted path from the root field
+	// to mask only that location (e.g. `metadata.request.card.number`)
 	MaskSensitiveData []string
 
-	// SensitiveDataMasker, function to modify sensitive value into something (default: `*****`)
+	// SensitiveDataMasker, function to modify sensitive value into something (default: `*` for every character),
+	// use `log.MaskFixed()` strategy to hide the length of the value
 	SensitiveDataMasker func(value string) string
 
+	// MaskStrategies is rules of field keys (like MaskSensitiveData) to be masked by their own strategy,
+	// e.g. `log.MaskKeepLast(4)` for card numbers, `log.MaskHMAC(key)` or `log.MaskRemove()`
+	MaskStrategies map[string]MaskStrategy
+
+	// RedactDetectors is built-in secret detectors to redact secrets inside messages, errors & string values
+	// e.g. bearer tokens, JWTs, credit card numbers, emails & AWS keys (default: none)
+	RedactDetectors []RedactDetector
+
+	// RedactPatterns is custom regex patterns to be redacted like RedactDetectors
+	RedactPatterns []string
+
 	// UseJSON is a toggle to format log as json (default: false)
 	UseJSON bool
 
@@ -52,24 +72,98 @@ type Config struct {
 	UseColor bool
 
 	// UseMultiWriters is a toggle to print log into log file and log console
-	// note: FilePath must be filled
+	// note: without FilePath, log is printed into log console only
 	UseMultiWriters bool
 
 	// FilePath a file path to write the log as a file
 	// note: if you fill the file path, your console log will be empty.
 	FilePath string
 
+	// FileMaxSize is maximum size in megabytes of log file before it gets rotated (default: 0, no size rotation)
+	FileMaxSize int
+
+	// FileRotateEvery is time-based log file rotation, `hourly` | `daily` (default: no time rotation)
+	FileRotateEvery RotateInterval
+
+	// FileMaxBackups is maximum number of rotated log files to retain (default: 0, retain all)
+	FileMaxBackups int
+
+	// FileMaxAge is maximum days to retain rotated log files (default: 0, retain all)
+	FileMaxAge int
+
+	// FileCompress is a toggle to gzip rotated log files (default: false)
+	FileCompress bool
+
+	// FileReopenOnSIGHUP is a toggle to reopen log file on SIGHUP for logrotate compatibility (default: false)
+	FileReopenOnSIGHUP bool
+
+	// UseAsync is a toggle to write log in background through a bounded queue,
+	// so slow disks don't stall your app (default: false)
+	UseAsync bool
+
+	// AsyncQueueSize is maximum number of logs waiting to be written (default: 1024)
+	AsyncQueueSize int
+
+	// AsyncOverflow is what to do when the queue is full,
+	// `block` | `drop_newest` | `drop_oldest` | `drop_below_level` (default: block)
+	AsyncOverflow OverflowPolicy
+
+	// AsyncDropLevel is level below which logs are dropped on `drop_below_level` policy (default: DEBUG)
+	AsyncDropLevel Level
+
+	// SampleFirst is number of repeated logs (same level & message) written per SampleInterval before sampling,
+	// e.g. a hot loop emitting the same error (default: 0, no sampling)
+	SampleFirst int
+
+	// SampleThereafter is to write every Mth repeated log after SampleFirst per SampleInterval (default: 0, drop them)
+	SampleThereafter int
+
+	// SampleInterval is interval of sampling counter (default: 1 second)
+	SampleInterval time.Duration
+
+	// RateLimit is maximum number of repeated logs per second by token bucket (default: 0, no rate limit)
+	RateLimit float64
+
+	// RateBurst is maximum number of repeated logs written at once (default: RateLimit)
+	RateBurst int
+
+	// SampleSummaryInterval is interval of summary log reporting how many logs were suppressed
+	// by sampling & rate limit (default: 10 seconds)
+	SampleSummaryInterval time.Duration
+
+	// DedupWindow is window to collapse consecutive identical logs (same level, message, error & fields)
+	// into a single `last message repeated N times` log (default: 0, no deduplication),
+	// note: every log is hashed by its printed fields which costs a few microseconds per log
+	DedupWindow time.Duration
+
+	// DebugOnError is a toggle to keep logs below Level per request (by request id of the context)
+	// and write them retroactively once an error or fatal log occurs in the request (default: false)
+	DebugOnError bool
+
+	// DebugOnErrorBufferSize is number of the latest logs kept per request (default: 100)
+	DebugOnErrorBufferSize int
+
+	// DebugOnErrorMaxRequests is number of requests kept at once, the least recent is discarded first (default: 10000)
+	DebugOnErrorMaxRequests int
+
 	// Engine is logger to be used
 	Engine Engine
 }
 
-// SetConfig is function to customize log configuration
+// SetConfig is function to customize log configuration,
+// it is safe to be called at runtime (e.g. on config reload): the active logger is swapped atomically,
+// then the previous one is closed once its in-flight logs are written, see retire.
+// An error wrapping ErrRetireTimeout means the new config is applied but the previous logger is still closing
 func SetConfig(config *Config) error {
 	var (
 		err          error
 		newLogger    logger.ILogger
 		configLogger logger.Config
 		engineLogger logger.Engine
+		sampler      *logger.Sampler
+		deduper      *logger.Deduper
+		buffer       *logger.RequestBuffer
+		namedLevels  map[string]Level
 	)
 
 	if config != nil {
@@ -83,9 +177,18 @@ func SetConfig(config *Config) error {
 			errStackLevel = *config.StackLevel
 		}
 
-		maskSensitiveData := make(map[string]struct{})
-		for _, key := range config.MaskSensitiveData {
-			maskSensitiveData[key] = struct{}{}
+		var sensitiveMatcher *logger.SensitiveMatcher
+		sensitiveMatcher, err = logger.NewSensitiveMatcher(config.MaskSensitiveData, config.MaskStrategies)
+		if err != nil {
+			return err
+		}
+
+		var redactor *logger.Redactor
+		if len(config.RedactDetectors) > 0 || len(config.RedactPatterns) > 0 {
+			redactor, err = logger.NewRedactor(config.RedactDetectors, config.RedactPatterns)
+			if err != nil {
+				return err
+			}
 		}
 
 		configLogger = logger.Config{
@@ -101,68 +204,220 @@ func SetConfig(config *Config) error {
 			StackMarshaller:      config.StackMarshaller,
 			UseJSON:              config.UseJSON,
 			UseColor:             config.UseColor,
-			SensitiveFields:      maskSensitiveData,
+			SensitiveMatcher:     sensitiveMatcher,
 			SensitiveFieldMasker: config.SensitiveDataMasker,
+			Redactor:             redactor,
 			UseMultiWriters:      config.UseMultiWriters,
 			File:                 config.FilePath,
+			FileRotation: writer.Rotation{
+				MaxSize:        config.FileMaxSize,
+				Every:          config.FileRotateEvery,
+				MaxBackups:     config.FileMaxBackups,
+				MaxAge:         config.FileMaxAge,
+				Compress:       config.FileCompress,
+				ReopenOnSIGHUP: config.FileReopenOnSIGHUP,
+			},
+		}
+		if config.UseAsync {
+			configLogger.Async = &writer.AsyncConfig{
+				QueueSize: config.AsyncQueueSize,
+				Overflow:  config.AsyncOverflow,
+				DropLevel: int(config.AsyncDropLevel),
+			}
 		}
+		sampler = logger.NewSampler(logger.SamplerConfig{
+			Interval:        config.SampleInterval,
+			First:           config.SampleFirst,
+			Thereafter:      config.SampleThereafter,
+			RateLimit:       config.RateLimit,
+			RateBurst:       config.RateBurst,
+			SummaryInterval: config.SampleSummaryInterval,
+		})
+		deduper = logger.NewDeduper(config.DedupWindow)
+		if config.DebugOnError {
+			bufferSize := defaultBufferSize
+			if config.DebugOnErrorBufferSize > 0 {
+				bufferSize = config.DebugOnErrorBufferSize
+			}
+			maxRequests := defaultBufferMaxRequests
+			if config.DebugOnErrorMaxRequests > 0 {
+				maxRequests = config.DebugOnErrorMaxRequests
+			}
+			buffer = logger.NewRequestBuffer(bufferSize, maxRequests, bufferTTL)
+		}
+		namedLevels = config.NamedLevels
 		engineLogger = config.Engine
 	}
 
-	newLogger, err = NewLogger(configLogger, engineLogger)
+	configLogger.AtomicLevel = rlevel
+	newLogger, err = newEngine(configLogger, engineLogger)
 	if err != nil {
 		return err
 	}
-	rlogger = newLogger
-	return nil
+	rlevel.SetLevel(configLogger.Level)
+	rnamed.SetLevels(namedLevels)
+	oldLogger := ractive.Swap(newActiveLogger(newLogger, sampler, deduper, buffer))
+	return oldLogger.retire()
+}
+
+// Sync flushes buffered logs of the active logger, e.g. call it before your app exits
+func Sync() error {
+	l := acquire()
+	defer l.release()
+	return l.logger.Sync()
+}
+
+// Close flushes buffered logs then releases resources (e.g. log file) of the active logger,
+// call it once when shutting down your app
+func Close() error {
+	l := acquire()
+	defer l.release()
+	l.stop()
+	return l.logger.Close()
+}
+
+// Trace prints log on trace level, below debug for very chatty diagnostics
+func Trace(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(TraceLevel, fields, err, message) {
+		return
+	}
+	l.logger.Trace(fields, err, message)
 }
 
 // Debug prints log on debug level
 func Debug(ctx context.Context, err error, metadata KV, message string) {
-	rlogger.Debug(buildFields(ctx, metadata), err, message)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(DebugLevel, fields, err, message) {
+		return
+	}
+	l.logger.Debug(fields, err, message)
 }
 
 // Info prints log on info level
 func Info(ctx context.Context, err error, metadata KV, message string) {
-	rlogger.Info(buildFields(ctx, metadata), err, message)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(InfoLevel, fields, err, message) {
+		return
+	}
+	l.logger.Info(fields, err, message)
 }
 
 // Warn prints log on warn level
 func Warn(ctx context.Context, err error, metadata KV, message string) {
-	rlogger.Warn(buildFields(ctx, metadata), err, message)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(WarnLevel, fields, err, message) {
+		return
+	}
+	l.logger.Warn(fields, err, message)
 }
 
 // Error prints log on error level
 func Error(ctx context.Context, err error, metadata KV, message string) {
-	rlogger.Error(buildFields(ctx, metadata), err, message)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(ErrorLevel, fields, err, message) {
+		return
+	}
+	l.logger.Error(fields, err, message)
 }
 
 // Fatal prints log on fatal level
 func Fatal(ctx context.Context, err error, metadata KV, message string) {
-	rlogger.Fatal(buildFields(ctx, metadata), err, message)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	l.admit(FatalLevel, fields, err, message)
+	l.logger.Fatal(fields, err, message)
+}
+
+// Panic prints log on panic level then panics, so deferred recovery & cleanup still run unlike Fatal
+func Panic(ctx context.Context, err error, metadata KV, message string) {
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	l.admit(PanicLevel, fields, err, message)
+	l.logger.Panic(fields, err, message)
+}
+
+// Tracef prints log on trace level like fmt.Printf
+func Tracef(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(TraceLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Tracef(fields, err, formatedMsg, args...)
 }
 
 // Debugf prints log on debug level like fmt.Printf
 func Debugf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
-	rlogger.Debugf(buildFields(ctx, metadata), err, formatedMsg, args...)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(DebugLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Debugf(fields, err, formatedMsg, args...)
 }
 
 // Infof prints log on info level like fmt.Printf
 func Infof(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
-	rlogger.Infof(buildFields(ctx, metadata), err, formatedMsg, args...)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(InfoLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Infof(fields, err, formatedMsg, args...)
 }
 
 // Warnf prints log on warn level like fmt.Printf
 func Warnf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
-	rlogger.Warnf(buildFields(ctx, metadata), err, formatedMsg, args...)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(WarnLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Warnf(fields, err, formatedMsg, args...)
 }
 
 // Errorf prints log on error level like fmt.printf
 func Errorf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
-	rlogger.Errorf(buildFields(ctx, metadata), err, formatedMsg, args...)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	if !l.admit(ErrorLevel, fields, err, formatedMsg, args...) {
+		return
+	}
+	l.logger.Errorf(fields, err, formatedMsg, args...)
 }
 
 // Fatalf prints log on fatal level like fmt.printf
 func Fatalf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
-	rlogger.Fatalf(buildFields(ctx, metadata), err, formatedMsg, args...)
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	l.admit(FatalLevel, fields, err, formatedMsg, args...)
+	l.logger.Fatalf(fields, err, formatedMsg, args...)
+}
+
+// Panicf prints log on panic level like fmt.Printf then panics
+func Panicf(ctx context.Context, err error, metadata KV, formatedMsg string, args ...interface{}) {
+	l := acquire()
+	defer l.release()
+	fields := buildFields(ctx, metadata)
+	l.admit(PanicLevel, fields, err, formatedMsg, args...)
+	l.logger.Panicf(fields, err, formatedMsg, args...)
 }
@@ -1,7 +1,15 @@
 package log
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/rizanw/go-log/logger"
+	"github.com/rizanw/go-log/logger/slog"
+	"github.com/rizanw/go-log/logger/writer"
 	"github.com/rizanw/go-log/logger/zap"
 	"github.com/rizanw/go-log/logger/zerolog"
 )
@@ -15,27 +23,268 @@ type (
 
 	// Logger interface
 	Logger = logger.ILogger
+
+	// RotateInterval of log file rotation
+	RotateInterval = writer.Interval
+
+	// OverflowPolicy of async log when its queue is full
+	OverflowPolicy = writer.OverflowPolicy
+
+	// RedactDetector is built-in secret detector
+	RedactDetector = logger.Detector
+
+	// MaskStrategy is how a sensitive value is masked
+	MaskStrategy = logger.MaskStrategy
+
+	// KeyProvider provides keys to encrypt & decrypt sensitive values
+	KeyProvider = logger.KeyProvider
 )
 
 // Level options
 const (
+	TraceLevel = logger.TraceLevel
 	DebugLevel = logger.DebugLevel
 	InfoLevel  = logger.InfoLevel
 	WarnLevel  = logger.WarnLevel
 	ErrorLevel = logger.ErrorLevel
 	FatalLevel = logger.FatalLevel
+	PanicLevel = logger.PanicLevel
 )
 
 // Engine options
 const (
 	Zap     Engine = logger.EngineZap
 	Zerolog Engine = logger.EngineZerolog
+	Slog    Engine = logger.EngineSlog
+)
+
+// ParseEngine returns the engine of the given case insensitive name, e.g. zap or ZAP
+func ParseEngine(name string) (Engine, error) {
+	return logger.ParseEngine(name)
+}
+
+// Log file rotation interval options
+const (
+	RotateHourly RotateInterval = writer.Hourly
+	RotateDaily  RotateInterval = writer.Daily
+)
+
+// Async log overflow policy options
+const (
+	OverflowBlock          OverflowPolicy = writer.Block
+	OverflowDropNewest     OverflowPolicy = writer.DropNewest
+	OverflowDropOldest     OverflowPolicy = writer.DropOldest
+	OverflowDropBelowLevel OverflowPolicy = writer.DropBelowLevel
+)
+
+// Secret redact detector options
+const (
+	RedactBearer     RedactDetector = logger.DetectorBearer
+	RedactJWT        RedactDetector = logger.DetectorJWT
+	RedactCreditCard RedactDetector = logger.DetectorCreditCard
+	RedactEmail      RedactDetector = logger.DetectorEmail
+	RedactAWSKey     RedactDetector = logger.DetectorAWSKey
+)
+
+// Mask strategy options
+var (
+	MaskFixed    = logger.MaskFixed
+	MaskKeepLast = logger.MaskKeepLast
+	MaskHMAC     = logger.MaskHMAC
+	MaskEmail    = logger.MaskEmail
+	MaskRemove   = logger.MaskRemove
+	MaskFunc     = logger.MaskFunc
+	MaskEncrypt  = logger.MaskEncrypt
 )
 
+// Encrypted value helpers of MaskEncrypt strategy
 var (
-	rlogger, _ = NewLogger(logger.Config{IsDevelopment: true}, logger.EngineZerolog)
+	NewStaticKeyProvider = logger.NewStaticKeyProvider
+	DecryptValue         = logger.DecryptValue
+	DecryptLog           = logger.DecryptLog
 )
 
+// activeLogger is the logger in use by log package,
+// it counts in-flight logs so it can be closed safely once replaced by SetConfig
+type activeLogger struct {
+	logger   Logger
+	inflight atomic.Int64
+	retired  atomic.Bool
+
+	// drained is closed once the logger is retired and its last in-flight log is released
+	drained     chan struct{}
+	drainedOnce sync.Once
+
+	// sampler suppresses repeated logs, nil when sampling is disabled
+	sampler *logger.Sampler
+
+	// deduper collapses consecutive identical logs, nil when deduplication is disabled
+	deduper *logger.Deduper
+
+	// buffer keeps logs below minimum level per request for debug on error, nil when it is disabled
+	buffer *logger.RequestBuffer
+
+	// done stops background jobs (e.g. sampling summary) once the logger is closed
+	done     chan struct{}
+	jobs     sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// ErrRetireTimeout is returned by SetConfig when the previous logger still has in-flight logs after the wait,
+// the new config is live anyway and the previous logger is closed once they finish, so it is safe to only report it
+var ErrRetireTimeout = errors.New("log: previous logger not closed in time")
+
+var (
+	ractive atomic.Pointer[activeLogger]
+
+	// newEngine creates the engine of SetConfig, tests replace it to observe the engine lifecycle
+	newEngine = NewLogger
+
+	// retireTimeout is how long SetConfig waits for in-flight logs of the previous logger
+	retireTimeout = 10 * time.Second
+
+	// rlevel is minimum log level shared by every logger created by SetConfig
+	rlevel = logger.NewAtomicLevel(DebugLevel)
+)
+
+func init() {
+	l, _ := NewLogger(logger.Config{IsDevelopment: true, AtomicLevel: rlevel}, logger.EngineZerolog)
+	ractive.Store(newActiveLogger(l, nil, nil, nil))
+}
+
+func newActiveLogger(l Logger, sampler *logger.Sampler, deduper *logger.Deduper, buffer *logger.RequestBuffer) *activeLogger {
+	active := &activeLogger{
+		logger:  l,
+		sampler: sampler,
+		deduper: deduper,
+		buffer:  buffer,
+		drained: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if sampler != nil {
+		active.every(sampler.SummaryInterval(), active.summarize)
+	}
+	if deduper != nil {
+		active.every(deduper.Window(), active.flushExpiredRepeat)
+	}
+	if buffer != nil {
+		active.every(buffer.TTL(), buffer.Expire)
+	}
+	return active
+}
+
+// every runs the job periodically until the logger is stopped
+func (l *activeLogger) every(interval time.Duration, job func()) {
+	l.jobs.Add(1)
+	go func() {
+		defer l.jobs.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				job()
+			case <-l.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop stops background jobs then writes what they still hold (e.g. the last sampling summary)
+func (l *activeLogger) stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+		l.jobs.Wait()
+
+		l.flushRepeat()
+		l.summarize()
+	})
+}
+
+// acquire returns the active logger, call release once the log is written
+func acquire() *activeLogger {
+	for {
+		l := ractive.Load()
+		l.inflight.Add(1)
+		if !l.retired.Load() {
+			return l
+		}
+		// replaced in the meantime, retry with the new one
+		l.release()
+	}
+}
+
+func (l *activeLogger) release() {
+	if l.inflight.Add(-1) == 0 && l.retired.Load() {
+		l.drainedOnce.Do(func() { close(l.drained) })
+	}
+}
+
+// enabled reports whether the level reaches minimum level of the log,
+// the minimum level of the context (see SetCtxLevel) overrides the global one
+func (l *activeLogger) enabled(level Level, field logger.Field) bool {
+	if field.MinLevel != nil {
+		return level >= *field.MinLevel
+	}
+	return rlevel.Enabled(level)
+}
+
+// admit reports whether the log is to be written now: logs below minimum level are kept per request
+// for debug on error, then repeated logs are sampled & deduplicated. Fatal & panic logs write the pending
+// sampling summary & repeated log first
+func (l *activeLogger) admit(level Level, field logger.Field, err error, message string, args ...interface{}) bool {
+	if level >= FatalLevel {
+		// the process exits or panics right after, so what background jobs still hold is written first
+		l.flushRepeat()
+		l.summarize()
+	}
+	if !l.enabled(level, field) {
+		if l.buffer != nil && field.RequestID != "" {
+			l.bufferLog(level, field, err, message, args...)
+		}
+		return false
+	}
+	if l.sampler != nil && !l.sampler.Allow(level, message) {
+		return false
+	}
+
+	ok := l.dedup(level, field, err, message, args...)
+	if l.buffer != nil && field.RequestID != "" && level >= ErrorLevel {
+		l.flushBuffered(field.RequestID)
+	}
+	return ok
+}
+
+// retire waits for in-flight logs to finish then closes the logger,
+// when they are still blocked after retireTimeout (e.g. async writer on a stalled disk) it returns ErrRetireTimeout
+// and the logger is closed in background once they finish
+func (l *activeLogger) retire() error {
+	l.retired.Store(true)
+	// balances acquire, so drained is closed even when no log is in flight
+	l.inflight.Add(1)
+	l.release()
+
+	select {
+	case <-l.drained:
+		return l.close()
+	case <-time.After(retireTimeout):
+		go func() {
+			<-l.drained
+			_ = l.close()
+		}()
+		return fmt.Errorf("%w: %d in-flight logs after %s, it is closed once they finish",
+			ErrRetireTimeout, l.inflight.Load(), retireTimeout)
+	}
+}
+
+func (l *activeLogger) close() error {
+	l.stop()
+	return l.logger.Close()
+}
+
 // NewLogger creates a logger instance based on selected logger engine
 func NewLogger(config logger.Config, engine logger.Engine) (Logger, error) {
 	var (
@@ -48,6 +297,8 @@ func NewLogger(config logger.Config, engine logger.Engine) (Logger, error) {
 		l, err = zerolog.New(&config)
 	case logger.EngineZap:
 		l, err = zap.New(&config)
+	case logger.EngineSlog:
+		l, err = slog.New(&config)
 	default:
 		l, err = zerolog.New(&config)
 	}
@@ -57,3 +308,14 @@ func NewLogger(config logger.Config, engine logger.Engine) (Logger, error) {
 
 	return l, err
 }
+
+// DroppedLogs returns number of logs dropped by async log overflow policy of the active logger
+func DroppedLogs() uint64 {
+	l := acquire()
+	defer l.release()
+
+	if counter, ok := l.logger.(interface{ Dropped() uint64 }); ok {
+		return counter.Dropped()
+	}
+	return 0
+}
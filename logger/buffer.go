@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Entry is a log kept to be written later, e.g. debug logs of a request written once the request fails
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Field   Field
+	Err     error
+	Message string
+}
+
+// RequestBuffer keeps the latest logs of every request in a bounded ring buffer,
+// so logs below the minimum level can be written retroactively when the request fails
+type RequestBuffer struct {
+	size        int
+	maxRequests int
+	ttl         time.Duration
+
+	mu       sync.Mutex
+	requests map[string]*list.Element
+	// lru orders requests by their latest log, the front is the most recent
+	lru *list.List
+}
+
+type requestRing struct {
+	requestID string
+	// entries grows up to the buffer size, most requests log far less than the size
+	entries []Entry
+	// next is the index overwritten by the next entry once entries is full
+	next     int
+	lastSeen time.Time
+}
+
+// NewRequestBuffer creates buffer keeping the latest size logs per request for at most maxRequests requests,
+// requests without a log within ttl are discarded by Expire
+func NewRequestBuffer(size, maxRequests int, ttl time.Duration) *RequestBuffer {
+	return &RequestBuffer{
+		size:        size,
+		maxRequests: maxRequests,
+		ttl:         ttl,
+		requests:    make(map[string]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+// TTL returns how long an idle request is kept
+func (b *RequestBuffer) TTL() time.Duration {
+	return b.ttl
+}
+
+// Add keeps the entry of the request, the oldest entry is overwritten once the ring is full
+func (b *RequestBuffer) Add(requestID string, entry Entry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	element, ok := b.requests[requestID]
+	if !ok {
+		if b.lru.Len() >= b.maxRequests {
+			b.remove(b.lru.Back())
+		}
+		element = b.lru.PushFront(&requestRing{requestID: requestID})
+		b.requests[requestID] = element
+	}
+	b.lru.MoveToFront(element)
+
+	ring := element.Value.(*requestRing)
+	if len(ring.entries) < b.size {
+		ring.entries = append(ring.entries, entry)
+	} else {
+		ring.entries[ring.next] = entry
+		ring.next = (ring.next + 1) % b.size
+	}
+	ring.lastSeen = entry.Time
+}
+
+// Take returns kept entries of the request from the oldest, then empties its ring
+func (b *RequestBuffer) Take(requestID string) []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	element, ok := b.requests[requestID]
+	if !ok {
+		return nil
+	}
+	ring := element.Value.(*requestRing)
+
+	var entries []Entry
+	entries = append(entries, ring.entries[ring.next:]...)
+	entries = append(entries, ring.entries[:ring.next]...)
+
+	// the ring is kept for the next logs of the request, only its entries are released
+	clear(ring.entries)
+	ring.entries = ring.entries[:0]
+	ring.next = 0
+	return entries
+}
+
+// Discard removes the request, e.g. once the request ends
+func (b *RequestBuffer) Discard(requestID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if element, ok := b.requests[requestID]; ok {
+		b.remove(element)
+	}
+}
+
+// Expire removes requests without a log within ttl, e.g. requests which never call Discard
+func (b *RequestBuffer) Expire() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	deadline := time.Now().Add(-b.ttl)
+	for element := b.lru.Back(); element != nil; element = b.lru.Back() {
+		if element.Value.(*requestRing).lastSeen.After(deadline) {
+			return
+		}
+		b.remove(element)
+	}
+}
+
+func (b *RequestBuffer) remove(element *list.Element) {
+	delete(b.requests, element.Value.(*requestRing).requestID)
+	b.lru.Remove(element)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func addMessages(b *RequestBuffer, requestID string, from, to int) {
+	for i := from; i < to; i++ {
+		b.Add(requestID, Entry{Time: time.Now(), Message: strconv.Itoa(i)})
+	}
+}
+
+func messages(entries []Entry) string {
+	var s string
+	for _, entry := range entries {
+		s += entry.Message
+	}
+	return s
+}
+
+func TestRequestBufferRing(t *testing.T) {
+	b := NewRequestBuffer(3, 10, time.Minute)
+
+	addMessages(b, "req", 0, 2)
+	if got := messages(b.Take("req")); got != "01" {
+		t.Errorf("Take() of a partial ring = %q, want 01", got)
+	}
+
+	addMessages(b, "req", 0, 5)
+	if got := messages(b.Take("req")); got != "234" {
+		t.Errorf("Take() of a wrapped ring = %q, want the latest 234", got)
+	}
+	if got := b.Take("req"); got != nil {
+		t.Errorf("Take() of an emptied ring = %v, want nil", got)
+	}
+
+	// the ring is reused after Take
+	addMessages(b, "req", 5, 7)
+	if got := messages(b.Take("req")); got != "56" {
+		t.Errorf("Take() after Take = %q, want 56", got)
+	}
+	if got := b.Take("unknown"); got != nil {
+		t.Errorf("Take() of an unknown request = %v, want nil", got)
+	}
+}
+
+func TestRequestBufferGrowsLazily(t *testing.T) {
+	b := NewRequestBuffer(1000, 10, time.Minute)
+
+	addMessages(b, "req", 0, 2)
+	ring := b.requests["req"].Value.(*requestRing)
+	if got := cap(ring.entries); got >= 1000 {
+		t.Errorf("ring of 2 logs has capacity %d, want it grown as logs come", got)
+	}
+
+	addMessages(b, "req", 2, 1500)
+	if got := len(ring.entries); got != 1000 {
+		t.Errorf("ring has %d entries, want the buffer size 1000", got)
+	}
+	if entries := b.Take("req"); len(entries) != 1000 || entries[0].Message != "500" || entries[999].Message != "1499" {
+		t.Errorf("Take() = %d entries from %s, want the latest 1000 from 500", len(entries), entries[0].Message)
+	}
+}
+
+func TestRequestBufferEviction(t *testing.T) {
+	b := NewRequestBuffer(3, 2, 20*time.Millisecond)
+
+	addMessages(b, "a", 0, 1)
+	addMessages(b, "b", 0, 1)
+	addMessages(b, "a", 1, 2)
+	// "b" is the least recently logged request
+	addMessages(b, "c", 0, 1)
+	if got := b.Take("b"); got != nil {
+		t.Errorf("least recent request is kept: %v", got)
+	}
+	if got := messages(b.Take("a")); got != "01" {
+		t.Errorf("Take(a) = %q, want 01", got)
+	}
+
+	b.Discard("a")
+	if _, ok := b.requests["a"]; ok {
+		t.Error("discarded request is kept")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	addMessages(b, "d", 0, 1)
+	b.Expire()
+	if _, ok := b.requests["c"]; ok {
+		t.Error("idle request is not expired")
+	}
+	if _, ok := b.requests["d"]; !ok {
+		t.Error("active request is expired")
+	}
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Repeat is a log collapsed by Deduper because it was repeated consecutively
+type Repeat struct {
+	Level   Level
+	Field   Field
+	Message string
+	Count   int
+	First   time.Time
+	Last    time.Time
+}
+
+// Metadata returns the repeat as log metadata
+func (r Repeat) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"repeated_message": r.Message,
+		"repeated":         r.Count,
+		"first_repeat_at":  r.First.Format(time.RFC3339Nano),
+		"last_repeat_at":   r.Last.Format(time.RFC3339Nano),
+	}
+}
+
+// Deduper collapses consecutive identical logs (same level, message, error & fields) within a window,
+// like syslog `last message repeated N times`. Fields are compared by their printed values, so nested pointers
+// (e.g. a new *User in metadata of every log) make logs different
+type Deduper struct {
+	window time.Duration
+	mu     sync.Mutex
+	last   *dedupEntry
+}
+
+type dedupEntry struct {
+	hash    uint64
+	level   Level
+	field   Field
+	message string
+	firstAt time.Time
+	repeat  Repeat
+}
+
+// NewDeduper creates deduper, it returns nil when the window is not positive
+func NewDeduper(window time.Duration) *Deduper {
+	if window <= 0 {
+		return nil
+	}
+	return &Deduper{window: window}
+}
+
+// Window returns window of consecutive identical logs to be collapsed
+func (d *Deduper) Window() time.Duration {
+	return d.window
+}
+
+// Check reports whether the log should be written, it also returns repeat of the previous log
+// to be written before this one when this log is not identical to the previous log
+func (d *Deduper) Check(level Level, field Field, err error, message string) (bool, *Repeat) {
+	if d == nil || level >= FatalLevel {
+		return true, d.Flush()
+	}
+
+	now := time.Now()
+	hash := dedupHash(level, field, err, message)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last != nil && d.last.hash == hash && now.Sub(d.last.firstAt) < d.window {
+		if d.last.repeat.Count == 0 {
+			d.last.repeat.First = now
+		}
+		d.last.repeat.Count++
+		d.last.repeat.Last = now
+		return false, nil
+	}
+
+	repeat := d.take()
+	d.last = &dedupEntry{
+		hash:    hash,
+		level:   level,
+		field:   field,
+		message: message,
+		firstAt: now,
+	}
+	return true, repeat
+}
+
+// Expired returns repeat of the previous log once its window is over, so it is not held until the next log
+func (d *Deduper) Expired() *Repeat {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last == nil || time.Since(d.last.firstAt) < d.window {
+		return nil
+	}
+	repeat := d.take()
+	d.last = nil
+	return repeat
+}
+
+// Flush returns repeat of the previous log regardless of its window, e.g. on close
+func (d *Deduper) Flush() *Repeat {
+	if d == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	repeat := d.take()
+	d.last = nil
+	return repeat
+}
+
+// take returns repeat of the previous log, or nil when it was not repeated
+func (d *Deduper) take() *Repeat {
+	if d.last == nil || d.last.repeat.Count == 0 {
+		return nil
+	}
+
+	repeat := d.last.repeat
+	repeat.Level = d.last.level
+	repeat.Field = d.last.field
+	repeat.Message = d.last.message
+	return &repeat
+}
+
+func dedupHash(level Level, field Field, err error, message string) uint64 {
+	errMessage := ""
+	if err != nil {
+		errMessage = err.Error()
+	}
+
+	// fmt prints maps sorted by key at any depth, so equal fields give equal hash regardless of insertion order,
+	// but slices are compared in order and pointers by address, so the minimum level override is left out.
+	// printing every field costs a few microseconds & allocations per log, growing with nested values
+	// (see BenchmarkDedupHash), which is only paid when deduplication is enabled
+	field.MinLevel = nil
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d\x00%s\x00%s\x00%+v", level, message, errMessage, field)
+	return h.Sum64()
+}
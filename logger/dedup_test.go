@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeduperCollapse(t *testing.T) {
+	d := NewDeduper(time.Minute)
+	field := Field{RequestID: "req-1", Metadata: map[string]interface{}{"a": 1, "b": map[string]interface{}{"x": 1, "y": 2}}}
+
+	if ok, repeat := d.Check(InfoLevel, field, nil, "same"); !ok || repeat != nil {
+		t.Fatalf("Check() of the first log = %v, %v, want it written", ok, repeat)
+	}
+	for i := 0; i < 3; i++ {
+		// maps are equal regardless of their insertion order
+		same := Field{RequestID: "req-1", Metadata: map[string]interface{}{"b": map[string]interface{}{"y": 2, "x": 1}, "a": 1}}
+		if ok, repeat := d.Check(InfoLevel, same, nil, "same"); ok || repeat != nil {
+			t.Fatalf("Check() of a repeated log = %v, %v, want it collapsed", ok, repeat)
+		}
+	}
+
+	ok, repeat := d.Check(InfoLevel, field, errors.New("other error"), "same")
+	if !ok || repeat == nil {
+		t.Fatalf("Check() of a different log = %v, %v, want it written with the repeat", ok, repeat)
+	}
+	if repeat.Count != 3 || repeat.Message != "same" || repeat.Level != InfoLevel || repeat.Field.RequestID != "req-1" {
+		t.Errorf("repeat = %+v, want 3 repeats of the previous log", repeat)
+	}
+	if repeat.First.IsZero() || repeat.Last.Before(repeat.First) {
+		t.Errorf("repeat first %s & last %s are not in order", repeat.First, repeat.Last)
+	}
+	if got := repeat.Metadata(); got["repeated"] != 3 || got["repeated_message"] != "same" {
+		t.Errorf("Metadata() = %v", got)
+	}
+}
+
+func TestDeduperDifferentLogs(t *testing.T) {
+	tests := map[string]func(d *Deduper) (bool, *Repeat){
+		"level":   func(d *Deduper) (bool, *Repeat) { return d.Check(WarnLevel, Field{}, nil, "msg") },
+		"message": func(d *Deduper) (bool, *Repeat) { return d.Check(InfoLevel, Field{}, nil, "other") },
+		"error":   func(d *Deduper) (bool, *Repeat) { return d.Check(InfoLevel, Field{}, errors.New("err"), "msg") },
+		"metadata": func(d *Deduper) (bool, *Repeat) {
+			return d.Check(InfoLevel, Field{Metadata: map[string]interface{}{"a": 1}}, nil, "msg")
+		},
+	}
+	for name, check := range tests {
+		d := NewDeduper(time.Minute)
+		d.Check(InfoLevel, Field{}, nil, "msg")
+		if ok, repeat := check(d); !ok || repeat != nil {
+			t.Errorf("%s: Check() = %v, %v, want a different log written without repeat", name, ok, repeat)
+		}
+	}
+
+	// the minimum level override of a request does not make logs different
+	d := NewDeduper(time.Minute)
+	debug, info := DebugLevel, InfoLevel
+	d.Check(InfoLevel, Field{MinLevel: &debug}, nil, "msg")
+	if ok, _ := d.Check(InfoLevel, Field{MinLevel: &info}, nil, "msg"); ok {
+		t.Error("Check() of logs differing by the minimum level only = written, want collapsed")
+	}
+}
+
+func TestDeduperWindow(t *testing.T) {
+	d := NewDeduper(20 * time.Millisecond)
+
+	d.Check(InfoLevel, Field{}, nil, "msg")
+	d.Check(InfoLevel, Field{}, nil, "msg")
+	if repeat := d.Expired(); repeat != nil {
+		t.Errorf("Expired() within the window = %+v, want nil", repeat)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if repeat := d.Expired(); repeat == nil || repeat.Count != 1 {
+		t.Errorf("Expired() after the window = %+v, want 1 repeat", repeat)
+	}
+	if repeat := d.Expired(); repeat != nil {
+		t.Errorf("Expired() twice = %+v, want nil", repeat)
+	}
+
+	// the same log after the window is written again
+	if ok, _ := d.Check(InfoLevel, Field{}, nil, "msg"); !ok {
+		t.Error("Check() after the window = collapsed, want written")
+	}
+	d.Check(InfoLevel, Field{}, nil, "msg")
+	time.Sleep(30 * time.Millisecond)
+	ok, repeat := d.Check(InfoLevel, Field{}, nil, "msg")
+	if !ok || repeat == nil || repeat.Count != 1 {
+		t.Errorf("Check() once the window is over = %v, %+v, want written with the repeat", ok, repeat)
+	}
+}
+
+func TestDeduperFlush(t *testing.T) {
+	d := NewDeduper(time.Minute)
+	d.Check(InfoLevel, Field{}, nil, "msg")
+	d.Check(InfoLevel, Field{}, nil, "msg")
+
+	// fatal logs are never collapsed and take the pending repeat
+	ok, repeat := d.Check(FatalLevel, Field{}, nil, "msg")
+	if !ok || repeat == nil || repeat.Count != 1 {
+		t.Errorf("Check() of fatal = %v, %+v, want written with the repeat", ok, repeat)
+	}
+	if repeat := d.Flush(); repeat != nil {
+		t.Errorf("Flush() after fatal = %+v, want nil", repeat)
+	}
+
+	d.Check(InfoLevel, Field{}, nil, "msg")
+	if repeat := d.Flush(); repeat != nil {
+		t.Errorf("Flush() of a log without repeat = %+v, want nil", repeat)
+	}
+
+	var disabled *Deduper
+	if NewDeduper(0) != nil {
+		t.Error("NewDeduper(0) is not nil")
+	}
+	if ok, repeat := disabled.Check(InfoLevel, Field{}, nil, "msg"); !ok || repeat != nil {
+		t.Errorf("nil Check() = %v, %v, want written", ok, repeat)
+	}
+}
+
+func BenchmarkDedupHash(b *testing.B) {
+	benchmarks := map[string]Field{
+		"empty": {},
+		"request": {
+			RequestID: "6c2e6a1a-4f3c-4a8e-9a36-0c4b1a2d3e4f",
+			TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanID:    "00f067aa0ba902b7",
+			Metadata:  map[string]interface{}{"method": "GET", "path": "/users", "status": 200},
+		},
+		"nested": {
+			RequestID: "6c2e6a1a-4f3c-4a8e-9a36-0c4b1a2d3e4f",
+			UserInfo:  map[string]interface{}{"id": 1, "roles": []string{"admin", "user"}},
+			Metadata: map[string]interface{}{
+				"request":  map[string]interface{}{"headers": map[string]interface{}{"accept": "*/*", "user-agent": "go"}, "query": "page=1"},
+				"response": map[string]interface{}{"status": 200, "bytes": 1024},
+			},
+		},
+	}
+	for name, field := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				dedupHash(InfoLevel, field, nil, "[HTTP] GET /users 200")
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// encryptedPrefix marks encrypted value in log, e.g. `enc:v1:<key id>:<base64 nonce & ciphertext>`
+const encryptedPrefix = "enc:v1:"
+
+var (
+	keyIDPattern     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	encryptedPattern = regexp.MustCompile(`enc:v1:([A-Za-z0-9_-]+):([A-Za-z0-9_-]+)`)
+)
+
+// KeyProvider provides AES keys (16, 24 or 32 bytes) to encrypt & decrypt sensitive values,
+// implement it to fetch keys from your secret manager or KMS
+type KeyProvider interface {
+	// CurrentKey returns id & key to encrypt new values, the id is written along the value
+	// and may only contain letters, digits, `_` and `-`
+	CurrentKey() (id string, key []byte, err error)
+
+	// Key returns key of the id to decrypt values, keep retired keys to read old logs
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyProvider is KeyProvider of fixed keys
+type StaticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewStaticKeyProvider creates key provider of the keys, new values are encrypted by the key of currentID
+func NewStaticKeyProvider(currentID string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid encryption key id: %q", id)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("invalid encryption key %q: %w", id, err)
+		}
+	}
+	if _, ok := keys[currentID]; currentID != "" && !ok {
+		return nil, fmt.Errorf("encryption key not found: %q", currentID)
+	}
+
+	return &StaticKeyProvider{
+		currentID: currentID,
+		keys:      keys,
+	}, nil
+}
+
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	if p.currentID == "" {
+		return "", nil, errors.New("no current encryption key")
+	}
+	return p.currentID, p.keys[p.currentID], nil
+}
+
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("encryption key not found: %q", id)
+	}
+	return key, nil
+}
+
+// MaskEncrypt encrypts the value by AES-GCM with the current key of the provider
+// (e.g. `enc:v1:2024-01:9vGm...`), so authorized staff can restore it by DecryptValue or DecryptLog,
+// the value is masked by `*****` when it can not be encrypted
+func MaskEncrypt(provider KeyProvider) MaskStrategy {
+	return MaskStrategy{mask: func(value string) string {
+		encrypted, err := EncryptValue(provider, value)
+		if err != nil {
+			return maskFixed
+		}
+		return encrypted
+	}}
+}
+
+// EncryptValue encrypts the value by AES-GCM with the current key of the provider
+func EncryptValue(provider KeyProvider, value string) (string, error) {
+	id, key, err := provider.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	if !keyIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid encryption key id: %q", id)
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	// key id is authenticated, so a value can not be swapped to another key
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(id))
+	return encryptedPrefix + id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue restores value encrypted by MaskEncrypt
+func DecryptValue(provider KeyProvider, encrypted string) (string, error) {
+	match := encryptedPattern.FindStringSubmatch(encrypted)
+	if match == nil || match[0] != encrypted {
+		return "", fmt.Errorf("invalid encrypted value: %q", encrypted)
+	}
+	id, payload := match[1], match[2]
+
+	key, err := provider.Key(id)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted value: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("invalid encrypted value: too short")
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("decrypt value of key %q: %w", id, err)
+	}
+	return string(plain), nil
+}
+
+// DecryptLog copies log lines from r to w with every encrypted value restored,
+// values are escaped in json lines, values which can not be decrypted are kept as is
+// and the first error is returned after every line is copied
+func DecryptLog(provider KeyProvider, r io.Reader, w io.Writer) error {
+	var (
+		decryptErr error
+		scanner    = bufio.NewScanner(r)
+		writer     = bufio.NewWriter(w)
+	)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		isJSON := strings.HasPrefix(strings.TrimSpace(line), "{")
+
+		line = encryptedPattern.ReplaceAllStringFunc(line, func(encrypted string) string {
+			plain, err := DecryptValue(provider, encrypted)
+			if err != nil {
+				if decryptErr == nil {
+					decryptErr = err
+				}
+				return encrypted
+			}
+			if isJSON {
+				quoted, _ := json.Marshal(plain)
+				return string(quoted[1 : len(quoted)-1])
+			}
+			return plain
+		})
+
+		if _, err := writer.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return decryptErr
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var (
+	testKey1 = bytes.Repeat([]byte{1}, 32)
+	testKey2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func newTestKeyProvider(t *testing.T, currentID string, keys map[string][]byte) *StaticKeyProvider {
+	t.Helper()
+
+	provider, err := NewStaticKeyProvider(currentID, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	provider := newTestKeyProvider(t, "k1", map[string][]byte{"k1": testKey1})
+
+	for _, value := range []string{"", "4111111111111111", `quote " backslash \ unicode ✓`} {
+		encrypted, err := EncryptValue(provider, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(encrypted, "enc:v1:k1:") {
+			t.Errorf("EncryptValue(%q) = %q, want enc:v1:k1: prefix", value, encrypted)
+		}
+		if value != "" && strings.Contains(encrypted, value) {
+			t.Errorf("EncryptValue(%q) = %q reveals the value", value, encrypted)
+		}
+
+		got, err := DecryptValue(provider, encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != value {
+			t.Errorf("DecryptValue() = %q, want %q", got, value)
+		}
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	encrypted, err := EncryptValue(newTestKeyProvider(t, "k1", map[string][]byte{"k1": testKey1}), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]*StaticKeyProvider{
+		"other key of the same id": newTestKeyProvider(t, "", map[string][]byte{"k1": testKey2}),
+		"unknown key id":           newTestKeyProvider(t, "", map[string][]byte{"k2": testKey1}),
+	}
+	for name, provider := range tests {
+		if got, err := DecryptValue(provider, encrypted); err == nil {
+			t.Errorf("%s: DecryptValue() = %q, want error", name, got)
+		}
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	provider := newTestKeyProvider(t, "k1", map[string][]byte{"k1": testKey1, "k2": testKey1})
+	encrypted, err := EncryptValue(provider, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := strings.TrimPrefix(encrypted, "enc:v1:k1:")
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := map[string]string{
+		"flipped ciphertext bit": "enc:v1:k1:" + base64.RawURLEncoding.EncodeToString(flipped),
+		"truncated ciphertext":   "enc:v1:k1:" + base64.RawURLEncoding.EncodeToString(sealed[:len(sealed)-1]),
+		"too short":              "enc:v1:k1:AAAA",
+		// the key id is authenticated, the same key under another id is rejected
+		"swapped key id": "enc:v1:k2:" + payload,
+		"not encrypted":  "secret",
+	}
+	for name, value := range tests {
+		if got, err := DecryptValue(provider, value); err == nil {
+			t.Errorf("%s: DecryptValue() = %q, want error", name, got)
+		}
+	}
+}
+
+func TestEncryptKeyRotation(t *testing.T) {
+	before := newTestKeyProvider(t, "2024-01", map[string][]byte{"2024-01": testKey1})
+	old, err := EncryptValue(before, "old secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the new key encrypts new values, the retired key still decrypts old logs
+	after := newTestKeyProvider(t, "2024-02", map[string][]byte{"2024-01": testKey1, "2024-02": testKey2})
+	current, err := EncryptValue(after, "new secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(current, "enc:v1:2024-02:") {
+		t.Errorf("EncryptValue() = %q, want encrypted by the current key 2024-02", current)
+	}
+
+	for encrypted, want := range map[string]string{old: "old secret", current: "new secret"} {
+		got, err := DecryptValue(after, encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("DecryptValue(%q) = %q, want %q", encrypted, got, want)
+		}
+	}
+}
+
+func TestNewStaticKeyProviderInvalid(t *testing.T) {
+	tests := map[string]struct {
+		currentID string
+		keys      map[string][]byte
+	}{
+		"invalid key id":      {currentID: "k 1", keys: map[string][]byte{"k 1": testKey1}},
+		"invalid key size":    {currentID: "k1", keys: map[string][]byte{"k1": []byte("short")}},
+		"unknown current key": {currentID: "k2", keys: map[string][]byte{"k1": testKey1}},
+	}
+	for name, tt := range tests {
+		if _, err := NewStaticKeyProvider(tt.currentID, tt.keys); err == nil {
+			t.Errorf("%s: NewStaticKeyProvider() returns no error", name)
+		}
+	}
+
+	provider := newTestKeyProvider(t, "", map[string][]byte{"k1": testKey1})
+	if _, err := EncryptValue(provider, "secret"); err == nil {
+		t.Error("EncryptValue() without current key returns no error")
+	}
+	if got := MaskEncrypt(provider).mask("secret"); got != maskFixed {
+		t.Errorf("MaskEncrypt() without current key = %q, want %q", got, maskFixed)
+	}
+}
+
+func TestDecryptLog(t *testing.T) {
+	provider := newTestKeyProvider(t, "k1", map[string][]byte{"k1": testKey1})
+	plain := `say "hi" \ path C:\tmp` + "\n"
+	encrypted, err := EncryptValue(provider, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonLine, err := json.Marshal(map[string]interface{}{"message": "login", "metadata": map[string]string{"password": encrypted}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := string(jsonLine) + "\n" +
+		"2024-07-23T14:52:00Z INF login password=" + encrypted + "\n" +
+		"2024-07-23T14:52:01Z INF unknown password=enc:v1:k9:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA\n"
+
+	var out bytes.Buffer
+	if err := DecryptLog(provider, strings.NewReader(input), &out); err == nil {
+		t.Error("DecryptLog() of an unknown key returns no error")
+	}
+	lines := strings.Split(out.String(), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("DecryptLog() wrote %d lines, want every line copied:\n%s", len(lines), out.String())
+	}
+
+	var decoded struct {
+		Metadata map[string]string `json:"metadata"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("decrypted json line is invalid: %v\n%s", err, lines[0])
+	}
+	if decoded.Metadata["password"] != plain {
+		t.Errorf("json password = %q, want %q", decoded.Metadata["password"], plain)
+	}
+	// text lines get the value as is, so its new line splits the line
+	if want := "password=" + plain; !strings.HasSuffix(lines[1]+"\n", want) {
+		t.Errorf("text line = %q, want suffix %q", lines[1], want)
+	}
+	if !strings.Contains(lines[3], "enc:v1:k9:") {
+		t.Errorf("value of an unknown key = %q, want kept as is", lines[3])
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+var engines = []Engine{EngineZap, EngineZerolog, EngineSlog}
+
+// String returns name of the engine, e.g. zap
+func (e Engine) String() string {
+	return string(e)
+}
+
+// ParseEngine returns the engine of the given case insensitive name, e.g. zap or ZAP
+func ParseEngine(name string) (Engine, error) {
+	for _, engine := range engines {
+		if strings.EqualFold(name, string(engine)) {
+			return engine, nil
+		}
+	}
+	return "", fmt.Errorf("unknown log engine: %q", name)
+}
+
+// MarshalText marshals the engine as its name, empty engine (the default one) is allowed
+func (e Engine) MarshalText() ([]byte, error) {
+	if e == "" {
+		return nil, nil
+	}
+	if _, err := ParseEngine(string(e)); err != nil {
+		return nil, err
+	}
+	return []byte(e), nil
+}
+
+// UnmarshalText parses the engine from its case insensitive name, empty name is the default engine
+func (e *Engine) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*e = ""
+		return nil
+	}
+
+	engine, err := ParseEngine(string(text))
+	if err != nil {
+		return err
+	}
+	*e = engine
+	return nil
+}
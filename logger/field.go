@@ -1,16 +1,54 @@
 package logger
 
 const (
-	FieldNameRequestID = "request_id"
-	FieldNameSource    = "source"
-	FieldNameUserInfo  = "user_info"
-	FieldNameMetadata  = "metadata"
+	FieldNameLogger     = "logger"
+	FieldNameRequestID  = "request_id"
+	FieldNameSource     = "source"
+	FieldNameUserInfo   = "user_info"
+	FieldNameMetadata   = "metadata"
+	FieldNameTraceID    = "trace_id"
+	FieldNameSpanID     = "span_id"
+	FieldNameTraceFlags = "trace_flags"
 )
 
+// reservedFieldNames is keys written by go-log itself
+var reservedFieldNames = map[string]bool{
+	FieldNameLogger:     true,
+	FieldNameRequestID:  true,
+	FieldNameSource:     true,
+	FieldNameUserInfo:   true,
+	FieldNameMetadata:   true,
+	FieldNameTraceID:    true,
+	FieldNameSpanID:     true,
+	FieldNameTraceFlags: true,
+	"timestamp":         true,
+	"level":             true,
+	"message":           true,
+	"line":              true,
+	"error":             true,
+	"stacktrace":        true,
+}
+
+// IsReservedFieldName reports whether the key is written by go-log itself (e.g. `request_id` or `message`),
+// so a field of the same key must not overwrite it
+func IsReservedFieldName(key string) bool {
+	return reservedFieldNames[key]
+}
+
 type Field struct {
-	RequestID string
-	Source    interface{}
-	UserInfo  interface{}
-	Metadata  map[string]interface{}
-	Fields    map[string]interface{}
+	// Name is name of the logger, e.g. `db.pool` of log.Named
+	Name       string
+	RequestID  string
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+	Source     interface{}
+	UserInfo   interface{}
+	Metadata   map[string]interface{}
+	Fields     map[string]interface{}
+	// MinLevel overrides minimum level of the logger for this log when set, e.g. debug for a single request
+	MinLevel *Level
+	// PC is program counter of the log call site when it is known upfront (e.g. of a slog record),
+	// engines print it as the caller instead of walking their own call stack
+	PC uintptr
 }
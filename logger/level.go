@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// AtomicLevel is a log level which can be changed safely at runtime,
+// engines read it on every log so changes apply without rebuilding the logger
+type AtomicLevel struct {
+	level atomic.Int32
+}
+
+// NewAtomicLevel creates atomic level starting at the given level
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.SetLevel(level)
+	return a
+}
+
+// Level returns current log level
+func (a *AtomicLevel) Level() Level {
+	return Level(a.level.Load())
+}
+
+// SetLevel changes current log level
+func (a *AtomicLevel) SetLevel(level Level) {
+	a.level.Store(int32(level))
+}
+
+// Enabled reports whether the given level reaches current log level
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return level >= a.Level()
+}
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP serves current log level as JSON on GET
+// and changes it on PUT with JSON body (e.g. `{"level":"debug"}`)
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+
+	switch r.Method {
+	case http.MethodGet:
+		_ = enc.Encode(levelPayload{Level: a.Level().String()})
+	case http.MethodPut:
+		var payload levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorPayload{Error: fmt.Sprintf("invalid request body: %s", err.Error())})
+			return
+		}
+
+		level, err := ParseLevel(payload.Level)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = enc.Encode(errorPayload{Error: err.Error()})
+			return
+		}
+
+		a.SetLevel(level)
+		_ = enc.Encode(levelPayload{Level: level.String()})
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_ = enc.Encode(errorPayload{Error: "only GET and PUT are supported"})
+	}
+}
+
+var levelNames = map[Level]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+	PanicLevel: "panic",
+}
+
+// String returns lower case name of the level, e.g. debug
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("Level(%d)", l)
+}
+
+// ParseLevel returns the level of the given case insensitive name, e.g. debug or DEBUG
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return DebugLevel, fmt.Errorf("unknown log level: %q", name)
+}
+
+// MarshalText marshals the level as its name, so it is written as e.g. `"warn"` in JSON or YAML
+func (l Level) MarshalText() ([]byte, error) {
+	name, ok := levelNames[l]
+	if !ok {
+		return nil, fmt.Errorf("unknown log level: %d", l)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalText parses the level from its case insensitive name, so it is read from e.g. `level: warn` in YAML
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// UnmarshalJSON parses the level from its name (e.g. `"warn"`) or its number (e.g. `3`),
+// so configs written before levels were marshaled by name are still read
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		return l.UnmarshalText([]byte(name))
+	}
+
+	var number int
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("invalid log level: %s", data)
+	}
+	if _, ok := levelNames[Level(number)]; !ok {
+		return fmt.Errorf("unknown log level: %d", number)
+	}
+	*l = Level(number)
+	return nil
+}
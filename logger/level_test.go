@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    Level
+		wantErr bool
+	}{
+		{data: `{"level":"warn"}`, want: WarnLevel},
+		{data: `{"level":"TRACE"}`, want: TraceLevel},
+		{data: `{"level":1}`, want: InfoLevel},
+		{data: `{"level":-1}`, want: TraceLevel},
+		{data: `{"level":5}`, want: PanicLevel},
+		{data: `{"level":"verbose"}`, wantErr: true},
+		{data: `{"level":9}`, wantErr: true},
+		{data: `{"level":1.5}`, wantErr: true},
+		{data: `{"level":true}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			var config struct {
+				Level Level `json:"level"`
+			}
+			err := json.Unmarshal([]byte(tt.data), &config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && config.Level != tt.want {
+				t.Errorf("Level = %s, want %s", config.Level, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelJSONRoundTrip(t *testing.T) {
+	for level := range levelNames {
+		data, err := json.Marshal(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Level
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != level {
+			t.Errorf("round trip of %s = %s", level, got)
+		}
+	}
+}
+
+func TestAtomicLevelServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantLevel  Level
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusOK, wantBody: `{"level":"info"}`, wantLevel: InfoLevel},
+		{name: "put", method: http.MethodPut, body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantBody: `{"level":"debug"}`, wantLevel: DebugLevel},
+		{name: "put case insensitive", method: http.MethodPut, body: `{"level":"ERROR"}`, wantStatus: http.StatusOK, wantBody: `{"level":"error"}`, wantLevel: ErrorLevel},
+		{name: "put trace", method: http.MethodPut, body: `{"level":"trace"}`, wantStatus: http.StatusOK, wantBody: `{"level":"trace"}`, wantLevel: TraceLevel},
+		{name: "put unknown level", method: http.MethodPut, body: `{"level":"verbose"}`, wantStatus: http.StatusBadRequest, wantBody: `unknown log level`, wantLevel: InfoLevel},
+		{name: "put empty level", method: http.MethodPut, body: `{}`, wantStatus: http.StatusBadRequest, wantBody: `unknown log level`, wantLevel: InfoLevel},
+		{name: "put invalid json", method: http.MethodPut, body: `level=debug`, wantStatus: http.StatusBadRequest, wantBody: `invalid request body`, wantLevel: InfoLevel},
+		{name: "put empty body", method: http.MethodPut, wantStatus: http.StatusBadRequest, wantBody: `invalid request body`, wantLevel: InfoLevel},
+		{name: "post", method: http.MethodPost, body: `{"level":"debug"}`, wantStatus: http.StatusMethodNotAllowed, wantBody: `only GET and PUT`, wantLevel: InfoLevel},
+		{name: "delete", method: http.MethodDelete, wantStatus: http.StatusMethodNotAllowed, wantBody: `only GET and PUT`, wantLevel: InfoLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := NewAtomicLevel(InfoLevel)
+			w := httptest.NewRecorder()
+			level.ServeHTTP(w, httptest.NewRequest(tt.method, "/log/level", strings.NewReader(tt.body)))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("content type = %q, want application/json", got)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if !json.Valid(w.Body.Bytes()) {
+				t.Errorf("body %q is not JSON", w.Body.String())
+			}
+			if tt.wantStatus == http.StatusMethodNotAllowed && w.Header().Get("Allow") != "GET, PUT" {
+				t.Errorf("Allow = %q, want GET, PUT", w.Header().Get("Allow"))
+			}
+			if got := level.Level(); got != tt.wantLevel {
+				t.Errorf("level = %v, want %v", got, tt.wantLevel)
+			}
+		})
+	}
+}
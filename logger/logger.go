@@ -1,9 +1,7 @@
 package logger
 
 import (
-	"os"
-	"path/filepath"
-	"strings"
+	"github.com/rizanw/go-log/logger/writer"
 )
 
 type (
@@ -15,6 +13,8 @@ type (
 
 	// ILogger interface
 	ILogger interface {
+		Trace(field Field, err error, message string)
+		Tracef(field Field, err error, format string, args ...interface{})
 		Debug(field Field, err error, message string)
 		Debugf(field Field, err error, format string, args ...interface{})
 		Info(field Field, err error, message string)
@@ -25,21 +25,35 @@ type (
 		Errorf(field Field, err error, format string, args ...interface{})
 		Fatal(field Field, err error, message string)
 		Fatalf(field Field, err error, format string, args ...interface{})
+		Panic(field Field, err error, message string)
+		Panicf(field Field, err error, format string, args ...interface{})
+
+		// Sync flushes buffered logs into every sink
+		Sync() error
+		// Close flushes buffered logs then releases resources (e.g. log file)
+		Close() error
+		// Write writes the entry at its time regardless of minimum level, without caller,
+		// e.g. buffered debug logs written once their request fails
+		Write(entry Entry)
 	}
 )
 
 // list of log level
 const (
-	DebugLevel Level = iota
+	TraceLevel Level = iota - 1
+	DebugLevel
 	InfoLevel
 	WarnLevel
 	ErrorLevel
 	FatalLevel
+	// PanicLevel logs then panics, so deferred recovery & cleanup still run unlike fatal
+	PanicLevel
 )
 
 const (
 	EngineZap     Engine = "zap"
 	EngineZerolog Engine = "zerolog"
+	EngineSlog    Engine = "slog"
 )
 
 type Config struct {
@@ -48,6 +62,7 @@ type Config struct {
 	IsDevelopment        bool
 	TimeFormat           string
 	Level                Level
+	AtomicLevel          *AtomicLevel
 	WithCaller           bool
 	CallerSkip           int
 	WithStack            bool
@@ -55,45 +70,73 @@ type Config struct {
 	StackMarshaller      func(err error) interface{}
 	SensitiveFields      map[string]struct{}
 	SensitiveFieldMasker func(value string) string
+	SensitiveMatcher     *SensitiveMatcher
+	Redactor             *Redactor
 	UseJSON              bool
 	UseColor             bool
 	UseMultiWriters      bool
 	File                 string
+	FileRotation         writer.Rotation
+	Async                *writer.AsyncConfig
 }
 
-// OpenLogFile will open log file or generate it if not exist
-func (c *Config) OpenLogFile() (*os.File, error) {
+// OpenLogFile will open log file or generate it if not exist,
+// the file is rotated based on FileRotation
+func (c *Config) OpenLogFile() (*writer.File, error) {
 	if c.File == "" {
 		return nil, nil
 	}
 
-	err := os.MkdirAll(filepath.Dir(c.File), 0755)
-	if err != nil && err != os.ErrExist {
-		return nil, err
+	return writer.NewFile(c.File, c.FileRotation)
+}
+
+// HasMasking reports whether sensitive fields, sensitive matcher or redactor is configured
+func (c *Config) HasMasking() bool {
+	return len(c.SensitiveFields) > 0 || !c.SensitiveMatcher.IsEmpty() || c.Redactor != nil
+}
+
+// Enabled reports whether the level reaches minimum level of the log,
+// which is the minimum level override of the field when set, otherwise the logger's current level
+func (c *Config) Enabled(level Level, field Field) bool {
+	if field.MinLevel != nil {
+		return level >= *field.MinLevel
 	}
+	return c.AtomicLevel.Enabled(level)
+}
 
-	return os.OpenFile(c.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+// Redact replaces secrets inside the string (e.g. message) when redactor is configured
+func (c *Config) Redact(s string) string {
+	return c.Redactor.Redact(s)
 }
 
-// MaskSensitiveData recursively masks sensitive data in the map
-func (c *Config) MaskSensitiveData(m map[string]interface{}) {
-	if c.SensitiveFieldMasker == nil {
-		c.SensitiveFieldMasker = maskFieldStar
+// RedactError returns the error with secrets of its message replaced,
+// the original error is still reachable by errors.Unwrap (e.g. for stack trace)
+func (c *Config) RedactError(err error) error {
+	if err == nil || c.Redactor == nil {
+		return err
+	}
+
+	message := err.Error()
+	if redacted := c.Redactor.Redact(message); redacted != message {
+		return &redactedError{err: err, message: redacted, redactor: c.Redactor}
 	}
-	for key, value := range m {
-		switch v := value.(type) {
-		case string:
-			// Mask specific keys
-			if _, ok := c.SensitiveFields[key]; ok {
-				m[key] = c.SensitiveFieldMasker(v)
-			}
-		case map[string]interface{}:
-			// Recursively call the function for nested maps
-			c.MaskSensitiveData(v)
-		}
+	return err
+}
+
+// MaskValue masks sensitive data of the root field value (e.g. source, user_info & metadata),
+// the key is the root of dotted path rules, the given value is never modified,
+// nil is returned when the root field must be removed
+func (c *Config) MaskValue(key string, value interface{}) interface{} {
+	masked, _ := newMaskWalker(c).maskChild(key, nil, value, 0)
+	if _, removed := masked.(removedValue); removed {
+		return nil
 	}
+	return masked
 }
 
-func maskFieldStar(s string) string {
-	return strings.Repeat("*", len(s))
+// MaskSensitiveData recursively masks sensitive data in the map of root fields,
+// it returns a masked copy (or the map itself when nothing is masked) so the caller's map is never modified
+func (c *Config) MaskSensitiveData(m map[string]interface{}) map[string]interface{} {
+	masked, _ := newMaskWalker(c).maskMap(m, 0)
+	return masked
 }
@@ -0,0 +1,472 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxMaskDepth is maximum nesting level to be masked, deeper values are replaced by MaskMaxDepth
+const maxMaskDepth = 32
+
+// placeholders of values which can not be masked safely
+const (
+	// MaskCycle replaces a value referring to itself
+	MaskCycle = "[CYCLE]"
+
+	// MaskMaxDepth replaces a value nested deeper than the depth limit
+	MaskMaxDepth = "[MAX_DEPTH]"
+)
+
+// maskWalker traverses a value to mask its sensitive fields,
+// it copies maps, slices & structs on write so the given value is never modified
+type maskWalker struct {
+	config   *Config
+	masker   func(value string) string
+	visiting map[visit]struct{}
+
+	// path is keys from the root field to the value being masked, e.g. [metadata request card]
+	path []string
+}
+
+// removedValue marks a key to be removed by MaskRemove strategy
+type removedValue struct{}
+
+// visit identifies a reference (pointer, map or slice) being traversed to detect cycles
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func newMaskWalker(c *Config) *maskWalker {
+	masker := c.SensitiveFieldMasker
+	if masker == nil {
+		masker = maskFieldStar
+	}
+	return &maskWalker{
+		config: c,
+		masker: masker,
+	}
+}
+
+// mask returns the masked value and whether it differs from the given value,
+// the given value itself is returned when nothing is masked
+func (w *maskWalker) mask(strategy *MaskStrategy, value interface{}, depth int) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return w.maskString(strategy, v)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return value, false
+		}
+		if depth >= maxMaskDepth {
+			return MaskMaxDepth, true
+		}
+		ref := visit{ptr: reflect.ValueOf(v).Pointer(), typ: reflect.TypeOf(v)}
+		if !w.enter(ref) {
+			return MaskCycle, true
+		}
+		defer w.leave(ref)
+		return w.maskMap(v, depth)
+	case json.Marshaler, encoding.TextMarshaler, error:
+		// values with their own representation (e.g. time.Time) are printed as is
+		if strategy != nil {
+			return w.maskWith(strategy, fmt.Sprint(v)), true
+		}
+		return value, false
+	}
+
+	return w.maskReflect(strategy, value, reflect.ValueOf(value), depth)
+}
+
+func (w *maskWalker) maskString(strategy *MaskStrategy, s string) (interface{}, bool) {
+	if strategy != nil {
+		return w.maskWith(strategy, s), true
+	}
+	if redacted := w.config.Redactor.Redact(s); redacted != s {
+		return redacted, true
+	}
+	return s, false
+}
+
+// maskMap copies the map on write, only once a value needs to be masked
+func (w *maskWalker) maskMap(m map[string]interface{}, depth int) (map[string]interface{}, bool) {
+	var masked map[string]interface{}
+	for key, value := range m {
+		newValue, changed := w.maskChild(key, nil, value, depth+1)
+		if !changed {
+			continue
+		}
+
+		if masked == nil {
+			masked = make(map[string]interface{}, len(m))
+			for k, v := range m {
+				masked[k] = v
+			}
+		}
+		if _, removed := newValue.(removedValue); removed {
+			delete(masked, key)
+			continue
+		}
+		masked[key] = newValue
+	}
+
+	if masked == nil {
+		return m, false
+	}
+	return masked, true
+}
+
+func (w *maskWalker) maskReflect(strategy *MaskStrategy, value interface{}, rv reflect.Value, depth int) (interface{}, bool) {
+	if depth >= maxMaskDepth {
+		return MaskMaxDepth, true
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		if masked, changed := w.maskString(strategy, rv.String()); changed {
+			return masked, true
+		}
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		if strategy != nil {
+			return w.maskWith(strategy, fmt.Sprint(value)), true
+		}
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return value, false
+		}
+		if rv.Kind() == reflect.Pointer {
+			ref := visit{ptr: rv.Pointer(), typ: rv.Type()}
+			if !w.enter(ref) {
+				return MaskCycle, true
+			}
+			defer w.leave(ref)
+		}
+		if masked, changed := w.mask(strategy, rv.Elem().Interface(), depth+1); changed {
+			return masked, true
+		}
+	case reflect.Map:
+		if rv.Len() == 0 {
+			return value, false
+		}
+		ref := visit{ptr: rv.Pointer(), typ: rv.Type()}
+		if !w.enter(ref) {
+			return MaskCycle, true
+		}
+		defer w.leave(ref)
+
+		var (
+			masked  = make(map[string]interface{}, rv.Len())
+			changed bool
+		)
+		for iter := rv.MapRange(); iter.Next(); {
+			key := mapKey(iter.Key())
+			newValue, ok := w.maskChild(key, nil, iter.Value().Interface(), depth+1)
+			changed = changed || ok
+			if _, removed := newValue.(removedValue); removed {
+				continue
+			}
+			masked[key] = newValue
+		}
+		if changed {
+			return masked, true
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return value, false
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// bytes are printed as a whole, not per element
+			if strategy != nil {
+				return w.maskWith(strategy, fmt.Sprint(value)), true
+			}
+			return value, false
+		}
+		if rv.Kind() == reflect.Slice {
+			ref := visit{ptr: rv.Pointer(), typ: rv.Type()}
+			if !w.enter(ref) {
+				return MaskCycle, true
+			}
+			defer w.leave(ref)
+		}
+
+		var (
+			masked  = make([]interface{}, rv.Len())
+			changed bool
+		)
+		for i := range masked {
+			newValue, ok := w.mask(strategy, rv.Index(i).Interface(), depth+1)
+			masked[i] = newValue
+			changed = changed || ok
+		}
+		if changed {
+			return masked, true
+		}
+	case reflect.Struct:
+		masked := make(map[string]interface{}, rv.NumField())
+		// a struct which can not be used as interface is always returned as the map
+		if w.maskStruct(strategy, rv, masked, depth) || !rv.CanInterface() {
+			return masked, true
+		}
+	}
+
+	return value, false
+}
+
+// maskStruct puts exported fields of the struct into the map keyed by their json name,
+// fields tagged `log:"-"` are omitted and fields tagged `log:"sensitive"` are masked.
+// json tag options & promoted fields are handled like encoding/json so masking keeps the printed shape
+func (w *maskWalker) maskStruct(strategy *MaskStrategy, rv reflect.Value, masked map[string]interface{}, depth int) bool {
+	var (
+		changed bool
+		rt      = rv.Type()
+
+		// own fields hide promoted fields of the same name, promoted fields of the same name hide each other
+		own       = make(map[string]struct{}, rt.NumField())
+		promoted  map[string]interface{}
+		ambiguous map[string]struct{}
+	)
+
+	for i := 0; i < rt.NumField(); i++ {
+		field, ok := structFieldOf(rt.Field(i))
+		if !ok {
+			continue
+		}
+
+		tag := rt.Field(i).Tag.Get("log")
+		fieldStrategy := strategy
+		if fieldStrategy == nil && tag == "sensitive" {
+			fieldStrategy = &MaskStrategy{}
+		}
+
+		value := rv.Field(i)
+		if field.embedded {
+			if tag == "-" {
+				changed = true
+				continue
+			}
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			fields := make(map[string]interface{})
+			changed = w.maskStruct(fieldStrategy, value, fields, depth) || changed
+			if promoted == nil {
+				promoted, ambiguous = make(map[string]interface{}), make(map[string]struct{})
+			}
+			for key, v := range fields {
+				if _, ok := promoted[key]; ok {
+					ambiguous[key] = struct{}{}
+				}
+				promoted[key] = v
+			}
+			continue
+		}
+
+		own[field.name] = struct{}{}
+		if tag == "-" {
+			changed = true
+			continue
+		}
+		if field.omitEmpty && isEmptyValue(value) {
+			continue
+		}
+
+		var newValue interface{}
+		if value.CanInterface() {
+			newValue, ok = w.maskChild(field.name, fieldStrategy, value.Interface(), depth+1)
+		} else {
+			// an unexported embedded struct with json name, it is printed as an object of its exported fields
+			newValue, ok = w.maskChild(field.name, fieldStrategy, value, depth+1)
+		}
+		changed = changed || ok
+		if _, removed := newValue.(removedValue); removed {
+			continue
+		}
+		if field.quoted && !ok {
+			newValue = quoteValue(value, newValue)
+		}
+		masked[field.name] = newValue
+	}
+
+	for key, value := range promoted {
+		if _, ok := own[key]; ok {
+			continue
+		}
+		if _, ok := ambiguous[key]; ok {
+			continue
+		}
+		masked[key] = value
+	}
+	return changed
+}
+
+// maskChild masks value of the key under the current path, it is masked by strategy of its sensitive parent
+// or strategy of the sensitive rule matching the key, removedValue is returned when the key must be removed
+func (w *maskWalker) maskChild(key string, strategy *MaskStrategy, value interface{}, depth int) (interface{}, bool) {
+	w.path = append(w.path, key)
+	defer func() { w.path = w.path[:len(w.path)-1] }()
+
+	if strategy == nil {
+		strategy = w.config.sensitiveStrategy(w.path)
+	}
+	if strategy != nil && strategy.remove {
+		return removedValue{}, true
+	}
+	if rv, ok := value.(reflect.Value); ok {
+		return w.maskReflect(strategy, nil, rv, depth)
+	}
+	return w.mask(strategy, value, depth)
+}
+
+func (w *maskWalker) maskWith(strategy *MaskStrategy, value string) string {
+	if strategy.mask != nil {
+		return strategy.mask(value)
+	}
+	return w.masker(value)
+}
+
+func (w *maskWalker) enter(ref visit) bool {
+	if _, ok := w.visiting[ref]; ok {
+		return false
+	}
+	if w.visiting == nil {
+		w.visiting = make(map[visit]struct{})
+	}
+	w.visiting[ref] = struct{}{}
+	return true
+}
+
+func (w *maskWalker) leave(ref visit) {
+	delete(w.visiting, ref)
+}
+
+// structField is a struct field like encoding/json prints it
+type structField struct {
+	name string
+
+	// embedded reports whether fields of an untagged embedded struct should be flattened
+	embedded bool
+
+	// omitEmpty & quoted are the json tag options `omitempty` & `string`
+	omitEmpty bool
+	quoted    bool
+}
+
+// structFieldOf returns the struct field like encoders print it, ok is false when the field is not printed
+func structFieldOf(field reflect.StructField) (structField, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return structField{}, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if field.Anonymous {
+		if !field.IsExported() && t.Kind() != reflect.Struct {
+			return structField{}, false
+		}
+		if name == "" && t.Kind() == reflect.Struct {
+			return structField{embedded: true}, true
+		}
+	} else if !field.IsExported() {
+		return structField{}, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+
+	f := structField{name: name}
+	for options != "" {
+		var option string
+		option, options, _ = strings.Cut(options, ",")
+		switch option {
+		case "omitempty":
+			f.omitEmpty = true
+		case "string":
+			switch t.Kind() {
+			case reflect.Bool,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64, reflect.String:
+				// like encoding/json, only pointers of unnamed type are dereferenced
+				f.quoted = field.Type.Kind() != reflect.Pointer || field.Type.Name() == ""
+			}
+		}
+	}
+	return f, true
+}
+
+// isEmptyValue reports whether the value is omitted by the json `omitempty` option
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// quoteValue returns the unmasked value of a field with json `string` option as encoding/json prints it,
+// e.g. 42 is printed as "42"
+func quoteValue(rv reflect.Value, value interface{}) interface{} {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return value
+		}
+		rv = rv.Elem()
+	}
+	b, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return value
+	}
+	return string(b)
+}
+
+// maskFieldStar is the default masker, it replaces every byte of the value with `*`
+func maskFieldStar(s string) string {
+	return strings.Repeat("*", len(s))
+}
+
+func mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+// sensitiveStrategy returns mask strategy of the path, or nil when the path is not sensitive,
+// the last element of the path is its key
+func (c *Config) sensitiveStrategy(path []string) *MaskStrategy {
+	if len(path) == 0 {
+		return nil
+	}
+	if strategy, ok := c.SensitiveMatcher.Match(path); ok {
+		return &strategy
+	}
+	if _, ok := c.SensitiveFields[path[len(path)-1]]; ok {
+		return &MaskStrategy{}
+	}
+	return nil
+}
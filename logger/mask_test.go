@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type shapeCredential struct {
+	Password string `json:"password"`
+	City     string `json:"city"`
+}
+
+type shapeAddress struct {
+	Street string `json:"street"`
+	Zip    string `json:"zip"`
+}
+
+type ShapeAudit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type shapeUser struct {
+	shapeCredential
+	*shapeAddress
+	*ShapeAudit
+	Profile shapeAddress `json:"profile"`
+
+	Name     string  `json:"name,omitempty"`
+	Nickname string  `json:"nickname,omitempty"`
+	Tags     []int   `json:"tags,omitempty"`
+	Age      int     `json:"age,string"`
+	Score    *int    `json:"score,string"`
+	Active   bool    `json:",string"`
+	Label    string  `json:"label,string"`
+	Zip      string  `json:"zip"`
+	Ignored  string  `json:"-"`
+	Parent   *string `json:"parent,omitempty"`
+}
+
+type shapeTagged struct {
+	shapeCredential `json:"credential"`
+	ID              int `json:"id"`
+}
+
+func TestMaskStructKeepsJSONShape(t *testing.T) {
+	score := 7
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{
+			name: "json tag options & promoted fields",
+			value: shapeUser{
+				shapeCredential: shapeCredential{Password: "secret", City: "Jakarta"},
+				shapeAddress:    &shapeAddress{Street: "Main", Zip: "hidden by own field"},
+				ShapeAudit:      &ShapeAudit{CreatedBy: "admin"},
+				Profile:         shapeAddress{Street: "Second", Zip: "123"},
+				Name:            "john",
+				Age:             30,
+				Score:           &score,
+				Active:          true,
+				Label:           `say "hi"`,
+				Zip:             "456",
+				Ignored:         "ignored",
+			},
+		},
+		{
+			name:  "nil embedded pointers & empty fields",
+			value: &shapeUser{shapeCredential: shapeCredential{Password: "secret"}},
+		},
+		{
+			name:  "tagged unexported embedded struct",
+			value: shapeTagged{shapeCredential: shapeCredential{Password: "secret", City: "Bandung"}, ID: 1},
+		},
+	}
+
+	matcher, err := NewSensitiveMatcher([]string{"password"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{
+		SensitiveMatcher:     matcher,
+		SensitiveFieldMasker: func(string) string { return "masked" },
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := decodeJSON(t, tt.value)
+			replacePassword(want)
+
+			got := decodeJSON(t, config.MaskValue("user", tt.value))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("masked shape differs:\n got %v\nwant %v", got, want)
+			}
+		})
+	}
+}
+
+func decodeJSON(t *testing.T, value interface{}) map[string]interface{} {
+	t.Helper()
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func replacePassword(m map[string]interface{}) {
+	for key, value := range m {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			replacePassword(v)
+		default:
+			if key == "password" {
+				m[key] = "masked"
+			}
+		}
+	}
+}
+
+func TestDefaultMasker(t *testing.T) {
+	matcher, err := NewSensitiveMatcher([]string{"password"}, map[string]MaskStrategy{"pin": MaskFixed()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{SensitiveMatcher: matcher}
+
+	got := config.MaskSensitiveData(map[string]interface{}{"password": "secret", "pin": "12"})
+	if got["password"] != "******" {
+		t.Errorf("password = %v, want length preserving ******", got["password"])
+	}
+	if got["pin"] != "*****" {
+		t.Errorf("pin = %v, want fixed *****", got["pin"])
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SensitiveMatcher matches keys of sensitive values by rules, every rule is case-insensitive:
+//   - name, e.g. `password` matches `Password` at any level
+//   - glob pattern, e.g. `*_token` matches `access_token` at any level
+//   - dotted path from the root field, e.g. `metadata.request.card.number` matches only that location,
+//     each element of the path may be a glob pattern, e.g. `metadata.*.password`
+//
+// glob patterns use path.Match syntax, but `*` & `?` match any character of a key including `/`,
+// only in dotted paths they stop at `.`, so `metadata.*` matches `metadata.token` and not `metadata.user.token`
+//
+// when several rules match, a path wins over a name and a name wins over a pattern
+type SensitiveMatcher struct {
+	names    map[string]MaskStrategy
+	patterns []sensitiveRule
+	paths    []sensitiveRule
+}
+
+type sensitiveRule struct {
+	pattern  string
+	strategy MaskStrategy
+}
+
+// NewSensitiveMatcher creates matcher of the rules masked by default strategy,
+// and of the rules masked by their own strategy
+func NewSensitiveMatcher(rules []string, strategies map[string]MaskStrategy) (*SensitiveMatcher, error) {
+	m := &SensitiveMatcher{
+		names: make(map[string]MaskStrategy),
+	}
+
+	for _, rule := range rules {
+		if err := m.add(rule, MaskStrategy{}); err != nil {
+			return nil, err
+		}
+	}
+	// sort rules of strategies, so the precedence between patterns does not depend on map order
+	strategyRules := make([]string, 0, len(strategies))
+	for rule := range strategies {
+		strategyRules = append(strategyRules, rule)
+	}
+	sort.Strings(strategyRules)
+	for _, rule := range strategyRules {
+		if err := m.add(rule, strategies[rule]); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *SensitiveMatcher) add(rule string, strategy MaskStrategy) error {
+	rule = strings.ToLower(strings.TrimSpace(rule))
+	if rule == "" {
+		return nil
+	}
+
+	// glob syntax is the one of path.Match, so it validates the rule
+	if _, err := path.Match(rule, ""); err != nil {
+		return fmt.Errorf("invalid sensitive data rule %q: %w", rule, err)
+	}
+
+	switch {
+	case strings.Contains(rule, "."):
+		m.paths = append(m.paths, sensitiveRule{pattern: rule, strategy: strategy})
+	case isGlob(rule):
+		m.patterns = append(m.patterns, sensitiveRule{pattern: rule, strategy: strategy})
+	default:
+		m.names[rule] = strategy
+	}
+	return nil
+}
+
+// Match returns mask strategy of the path (keys from the root field, e.g. [metadata request password]),
+// it reports false when the path is not sensitive
+func (m *SensitiveMatcher) Match(keys []string) (MaskStrategy, bool) {
+	if m == nil || len(keys) == 0 {
+		return MaskStrategy{}, false
+	}
+
+	if len(m.paths) > 0 {
+		dotted := strings.ToLower(strings.Join(keys, "."))
+		for _, rule := range m.paths {
+			if matchGlob(rule.pattern, dotted, '.') {
+				return rule.strategy, true
+			}
+		}
+	}
+
+	key := strings.ToLower(keys[len(keys)-1])
+	if strategy, ok := m.names[key]; ok {
+		return strategy, true
+	}
+	for _, rule := range m.patterns {
+		if matchGlob(rule.pattern, key, 0) {
+			return rule.strategy, true
+		}
+	}
+	return MaskStrategy{}, false
+}
+
+// IsEmpty reports whether the matcher has no rule
+func (m *SensitiveMatcher) IsEmpty() bool {
+	return m == nil || len(m.names) == 0 && len(m.patterns) == 0 && len(m.paths) == 0
+}
+
+// matchGlob reports whether name matches the valid glob pattern, `*` & `?` match any character but sep,
+// unlike path.Match which never matches `/` by them
+func matchGlob(pattern, name string, sep rune) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			for i, r := range name {
+				if matchGlob(pattern, name[i:], sep) {
+					return true
+				}
+				if r == sep {
+					return false
+				}
+			}
+			return matchGlob(pattern, "", sep)
+		case '?':
+			r, size := utf8.DecodeRuneInString(name)
+			if name == "" || r == sep {
+				return false
+			}
+			pattern, name = pattern[1:], name[size:]
+		case '[':
+			// a character class never contains `*` or `?`, path.Match matches it against a single character
+			end := classEnd(pattern)
+			r, size := utf8.DecodeRuneInString(name)
+			if ok, _ := path.Match(pattern[:end], string(r)); name == "" || !ok {
+				return false
+			}
+			pattern, name = pattern[end:], name[size:]
+		default:
+			if pattern[0] == '\\' {
+				pattern = pattern[1:]
+			}
+			r, size := utf8.DecodeRuneInString(pattern)
+			c, n := utf8.DecodeRuneInString(name)
+			if name == "" || r != c {
+				return false
+			}
+			pattern, name = pattern[size:], name[n:]
+		}
+	}
+	return name == ""
+}
+
+// classEnd returns the index after `]` closing the character class at the start of the valid pattern
+func classEnd(pattern string) int {
+	for i := 1; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\':
+			i++
+		case pattern[i] == ']' && i > 1 && !(i == 2 && pattern[1] == '^'):
+			return i + 1
+		}
+	}
+	return len(pattern)
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, `*?[\`)
+}
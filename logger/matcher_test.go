@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSensitiveMatcher(t *testing.T) {
+	m, err := NewSensitiveMatcher(
+		[]string{"Password", "*_token", "x-api-?ey", "metadata.*.secret", "source.a.*", "pin[0-9]", `literal\*`},
+		map[string]MaskStrategy{"metadata.request.card.number": MaskKeepLast(4), "card": MaskRemove()},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		// names, at any level & case-insensitive
+		{"password", true},
+		{"metadata.user.PASSWORD", true},
+		{"passwords", false},
+		{"card", true},
+
+		// globs
+		{"access_token", true},
+		{"metadata.Refresh_Token", true},
+		{"token", false},
+		{"x-api-key", true},
+		{"x-api-keys", false},
+		{"pin1", true},
+		{"pinx", false},
+		{`literal*`, true},
+		{"literalx", false},
+		// keys may contain `/`, e.g. header or url keys
+		{"metadata.oauth/access_token", true},
+
+		// dotted paths, only from the root field
+		{"metadata.request.card.number", true},
+		{"metadata.Request.Card.Number", true},
+		{"request.card.number", false},
+		{"metadata.request.card.number.last", false},
+		{"metadata.db.secret", true},
+		{"metadata.db.pool.secret", false},
+		{"secret", false},
+		{"source.a.b", true},
+		{"source.a", false},
+		{"source.a.b.c", false},
+		{"source.ab", false},
+		// `*` of a path matches `/` but not `.`
+		{"source.a.b/c", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			keys := strings.Split(tt.path, ".")
+			if _, ok := m.Match(keys); ok != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", keys, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := m.Match([]string{"metadata", "db.secret"}); !ok {
+		t.Error("Match() of a key containing `.` does not match the dotted path")
+	}
+	if strategy, _ := m.Match([]string{"metadata", "request", "card", "number"}); strategy.mask == nil {
+		t.Error("path rule of a strategy is matched by the default strategy")
+	}
+	if strategy, ok := m.Match([]string{"metadata", "card"}); !ok || !strategy.remove {
+		t.Error("name rule of a strategy is not matched by its strategy")
+	}
+}
+
+func TestSensitiveMatcherPrecedence(t *testing.T) {
+	m, err := NewSensitiveMatcher([]string{"*_number"}, map[string]MaskStrategy{
+		"card_number":                  MaskKeepLast(4),
+		"metadata.payment.card_number": MaskRemove(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"metadata.payment.card_number", "removed"},
+		{"metadata.card_number", "*****1111"},
+		{"metadata.phone_number", "default"},
+	}
+	for _, tt := range tests {
+		strategy, ok := m.Match(strings.Split(tt.path, "."))
+		if !ok {
+			t.Errorf("Match(%q) = false", tt.path)
+			continue
+		}
+		got := "default"
+		switch {
+		case strategy.remove:
+			got = "removed"
+		case strategy.mask != nil:
+			got = strategy.mask("4111111111111111")
+		}
+		if got != tt.want {
+			t.Errorf("Match(%q) masks into %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSensitiveMatcherInvalid(t *testing.T) {
+	for _, rule := range []string{"[", "metadata.[a-", `trailing\`} {
+		if _, err := NewSensitiveMatcher([]string{rule}, nil); err == nil {
+			t.Errorf("NewSensitiveMatcher(%q) returns no error", rule)
+		}
+	}
+
+	m, err := NewSensitiveMatcher([]string{"", "  "}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsEmpty() {
+		t.Error("matcher of blank rules is not empty")
+	}
+	if _, ok := (*SensitiveMatcher)(nil).Match([]string{"password"}); ok {
+		t.Error("nil matcher matches")
+	}
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// NamedLevels is minimum log level per logger name which can be changed safely at runtime,
+// a dotted name inherits the level of its closest parent, e.g. `db.pool` inherits `db`
+type NamedLevels struct {
+	levels atomic.Pointer[map[string]Level]
+}
+
+// NewNamedLevels creates named levels starting with the given rules
+func NewNamedLevels(levels map[string]Level) *NamedLevels {
+	n := &NamedLevels{}
+	n.SetLevels(levels)
+	return n
+}
+
+// Level returns minimum log level of the name, false when neither the name nor its parents has a rule
+func (n *NamedLevels) Level(name string) (Level, bool) {
+	levels := *n.levels.Load()
+	if len(levels) == 0 {
+		return DebugLevel, false
+	}
+
+	for {
+		if level, ok := levels[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return DebugLevel, false
+		}
+		name = name[:i]
+	}
+}
+
+// Levels returns a copy of every rule
+func (n *NamedLevels) Levels() map[string]Level {
+	return copyLevels(*n.levels.Load())
+}
+
+// SetLevels replaces every rule
+func (n *NamedLevels) SetLevels(levels map[string]Level) {
+	levels = copyLevels(levels)
+	n.levels.Store(&levels)
+}
+
+// SetLevel changes the rule of the name, rules of the other names are kept
+func (n *NamedLevels) SetLevel(name string, level Level) {
+	for {
+		current := n.levels.Load()
+		levels := copyLevels(*current)
+		levels[name] = level
+		if n.levels.CompareAndSwap(current, &levels) {
+			return
+		}
+	}
+}
+
+// RemoveLevel removes the rule of the name, so it inherits its parent again
+func (n *NamedLevels) RemoveLevel(name string) {
+	for {
+		current := n.levels.Load()
+		if _, ok := (*current)[name]; !ok {
+			return
+		}
+		levels := copyLevels(*current)
+		delete(levels, name)
+		if n.levels.CompareAndSwap(current, &levels) {
+			return
+		}
+	}
+}
+
+func copyLevels(levels map[string]Level) map[string]Level {
+	copied := make(map[string]Level, len(levels))
+	for name, level := range levels {
+		copied[name] = level
+	}
+	return copied
+}
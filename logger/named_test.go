@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNamedLevels(t *testing.T) {
+	n := NewNamedLevels(map[string]Level{"db": WarnLevel, "db.pool.conn": DebugLevel, "http": InfoLevel})
+
+	tests := []struct {
+		name   string
+		want   Level
+		wantOK bool
+	}{
+		{"db", WarnLevel, true},
+		{"db.pool", WarnLevel, true},
+		{"db.pool.conn", DebugLevel, true},
+		{"db.pool.conn.tx", DebugLevel, true},
+		{"http.client", InfoLevel, true},
+		// only dotted parents are inherited, not name prefixes
+		{"dbx", DebugLevel, false},
+		{"cache", DebugLevel, false},
+		{"", DebugLevel, false},
+	}
+	for _, tt := range tests {
+		if got, ok := n.Level(tt.name); got != tt.want || ok != tt.wantOK {
+			t.Errorf("Level(%q) = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNamedLevelsUpdate(t *testing.T) {
+	rules := map[string]Level{"db": WarnLevel}
+	n := NewNamedLevels(rules)
+	// the rules are copied, later changes of the given map are not applied
+	rules["db"] = ErrorLevel
+
+	n.SetLevel("db.pool", DebugLevel)
+	if got, _ := n.Level("db.pool"); got != DebugLevel {
+		t.Errorf("Level(db.pool) after SetLevel = %v, want debug", got)
+	}
+	if got, _ := n.Level("db"); got != WarnLevel {
+		t.Errorf("Level(db) after SetLevel of another name = %v, want warn", got)
+	}
+
+	n.RemoveLevel("db.pool")
+	n.RemoveLevel("unknown")
+	if got, _ := n.Level("db.pool"); got != WarnLevel {
+		t.Errorf("Level(db.pool) after RemoveLevel = %v, want inherited warn", got)
+	}
+
+	levels := n.Levels()
+	levels["db"] = TraceLevel
+	if got, _ := n.Level("db"); got != WarnLevel {
+		t.Errorf("Level(db) after changing Levels() = %v, want the copy not shared", got)
+	}
+
+	n.SetLevels(nil)
+	if _, ok := n.Level("db"); ok {
+		t.Error("Level(db) after SetLevels(nil) has a rule")
+	}
+}
+
+func TestNamedLevelsConcurrentSetLevel(t *testing.T) {
+	n := NewNamedLevels(nil)
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			n.SetLevel(name, ErrorLevel)
+			_, _ = n.Level(name + ".child")
+		}(name)
+	}
+	wg.Wait()
+
+	// every SetLevel keeps the rules of the others
+	if got := len(n.Levels()); got != len(names) {
+		t.Errorf("Levels() has %d rules, want %d", got, len(names))
+	}
+}
@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Detector is name of built-in secret detector
+type Detector string
+
+// list of built-in secret detector
+const (
+	DetectorBearer     Detector = "bearer"
+	DetectorJWT        Detector = "jwt"
+	DetectorCreditCard Detector = "credit_card"
+	DetectorEmail      Detector = "email"
+	DetectorAWSKey     Detector = "aws_key"
+)
+
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	// validate filters out false positives of the pattern, nil accepts every match
+	validate func(match string) bool
+}
+
+var builtinDetectors = map[Detector]detector{
+	DetectorBearer: {
+		// a token of any length in `Authorization: Bearer <token>`, elsewhere only a token-like word after bearer,
+		// so prose like "bearer of bad news" is kept
+		pattern: regexp.MustCompile(`(?i)\bauthorization\s*:\s*bearer\s+[a-z0-9\-._~+/]+=*|\bbearer\s+[a-z0-9\-._~+/]{16,}=*`),
+	},
+	DetectorJWT: {
+		pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+`),
+	},
+	DetectorCreditCard: {
+		pattern:  regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		validate: isLuhn,
+	},
+	DetectorEmail: {
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	DetectorAWSKey: {
+		pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b|(?i:aws_secret_access_key)\s*[:=]\s*[A-Za-z0-9/+=]{40}`),
+	},
+}
+
+// Redactor replaces secrets found inside free-form strings (messages, errors & values)
+type Redactor struct {
+	detectors []detector
+}
+
+// NewRedactor creates redactor from built-in detectors & custom regex patterns
+func NewRedactor(detectors []Detector, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+
+	for _, name := range detectors {
+		d, ok := builtinDetectors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown secret detector: %q", name)
+		}
+		d.name = string(name)
+		r.detectors = append(r.detectors, d)
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.detectors = append(r.detectors, detector{pattern: re})
+	}
+
+	return r, nil
+}
+
+// Redact replaces every secret inside the string
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	for _, d := range r.detectors {
+		replacement := "[REDACTED]"
+		if d.name != "" {
+			replacement = "[REDACTED:" + d.name + "]"
+		}
+
+		s = d.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if d.validate != nil && !d.validate(match) {
+				return match
+			}
+			return replacement
+		})
+	}
+	return s
+}
+
+// redactedError keeps the original error for stack trace while printing redacted message
+type redactedError struct {
+	err      error
+	message  string
+	redactor *Redactor
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+// Format prints the redacted message, `%+v` prints the redacted verbose form of the original error
+// (e.g. stack trace of pkg/errors) so encoders like zap still write it as errorVerbose
+func (e *redactedError) Format(s fmt.State, verb rune) {
+	if _, ok := e.err.(fmt.Formatter); ok && verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, e.redactor.Redact(fmt.Sprintf("%+v", e.err)))
+		return
+	}
+	_, _ = fmt.Fprintf(s, fmt.FormatString(s, verb), e.message)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// isLuhn validates card number by Luhn checksum to avoid redacting random numbers
+func isLuhn(number string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(number)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
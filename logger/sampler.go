@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SamplerConfig of repeated logs, logs are repeated when they have same level & message (or format)
+type SamplerConfig struct {
+	// Interval of sampling counter (default: 1 second)
+	Interval time.Duration
+
+	// First is number of repeated logs to be written per interval before sampling, 0 disables sampling
+	First int
+
+	// Thereafter is to write every Mth repeated log after First per interval (default: 0, drop them)
+	Thereafter int
+
+	// RateLimit is maximum number of repeated logs per second, 0 disables rate limiting
+	RateLimit float64
+
+	// RateBurst is maximum number of repeated logs written at once (default: RateLimit rounded up)
+	RateBurst int
+
+	// SummaryInterval is interval of summary log reporting suppressed logs (default: 10 seconds)
+	SummaryInterval time.Duration
+}
+
+// SampleSummary is number of suppressed logs of the same level & message
+type SampleSummary struct {
+	Level   Level
+	Message string
+	Count   uint64
+}
+
+// Metadata returns the summary as log metadata
+func (s SampleSummary) Metadata() map[string]interface{} {
+	return map[string]interface{}{
+		"level":   s.Level.String(),
+		"message": s.Message,
+		"count":   s.Count,
+	}
+}
+
+// Sampler suppresses repeated logs by first-N-then-every-Mth sampling & token bucket rate limiting
+type Sampler struct {
+	config  SamplerConfig
+	mu      sync.Mutex
+	entries map[sampleKey]*sampleEntry
+}
+
+type sampleKey struct {
+	level   Level
+	message string
+}
+
+type sampleEntry struct {
+	resetAt    time.Time
+	count      int
+	tokens     float64
+	refilledAt time.Time
+	lastSeen   time.Time
+	suppressed uint64
+}
+
+// NewSampler creates sampler, it returns nil when both sampling & rate limiting are disabled
+func NewSampler(config SamplerConfig) *Sampler {
+	if config.First <= 0 && config.RateLimit <= 0 {
+		return nil
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.RateBurst <= 0 {
+		config.RateBurst = int(config.RateLimit)
+		if float64(config.RateBurst) < config.RateLimit {
+			config.RateBurst++
+		}
+	}
+	if config.SummaryInterval <= 0 {
+		config.SummaryInterval = 10 * time.Second
+	}
+
+	return &Sampler{
+		config:  config,
+		entries: make(map[sampleKey]*sampleEntry),
+	}
+}
+
+// SummaryInterval returns interval of summary log
+func (s *Sampler) SummaryInterval() time.Duration {
+	return s.config.SummaryInterval
+}
+
+// Allow reports whether the log should be written, fatal & panic logs are always written
+func (s *Sampler) Allow(level Level, message string) bool {
+	if s == nil || level >= FatalLevel {
+		return true
+	}
+
+	now := time.Now()
+	key := sampleKey{level: level, message: message}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &sampleEntry{
+			tokens:     float64(s.config.RateBurst),
+			refilledAt: now,
+		}
+		s.entries[key] = entry
+	}
+	entry.lastSeen = now
+
+	allowed := true
+	if s.config.First > 0 {
+		if !now.Before(entry.resetAt) {
+			entry.count = 0
+			entry.resetAt = now.Add(s.config.Interval)
+		}
+		entry.count++
+		if entry.count > s.config.First {
+			allowed = s.config.Thereafter > 0 && (entry.count-s.config.First)%s.config.Thereafter == 0
+		}
+	}
+
+	if allowed && s.config.RateLimit > 0 {
+		entry.tokens += now.Sub(entry.refilledAt).Seconds() * s.config.RateLimit
+		if burst := float64(s.config.RateBurst); entry.tokens > burst {
+			entry.tokens = burst
+		}
+		entry.refilledAt = now
+
+		if entry.tokens >= 1 {
+			entry.tokens--
+		} else {
+			allowed = false
+		}
+	}
+
+	if !allowed {
+		entry.suppressed++
+	}
+	return allowed
+}
+
+// Summary returns suppressed logs since the last summary, sorted by most suppressed,
+// it also forgets logs which are no longer repeated
+func (s *Sampler) Summary() []SampleSummary {
+	if s == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var summaries []SampleSummary
+
+	s.mu.Lock()
+	for key, entry := range s.entries {
+		if entry.suppressed > 0 {
+			summaries = append(summaries, SampleSummary{
+				Level:   key.level,
+				Message: key.message,
+				Count:   entry.suppressed,
+			})
+			entry.suppressed = 0
+			continue
+		}
+		if now.Sub(entry.lastSeen) > s.config.SummaryInterval {
+			delete(s.entries, key)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Count > summaries[j].Count
+	})
+	return summaries
+}
@@ -0,0 +1,430 @@
+package slog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+	"github.com/rizanw/go-log/logger/writer"
+)
+
+const (
+	// LevelTrace is slog level for trace log since slog starts at debug
+	LevelTrace = slog.Level(-8)
+
+	// LevelFatal is slog level for fatal log since slog stops at error
+	LevelFatal = slog.Level(12)
+
+	// LevelPanic is slog level for panic log since slog stops at error
+	LevelPanic = slog.Level(16)
+)
+
+type Logger struct {
+	handlers map[logger.Level]slog.Handler
+	config   *logger.Config
+	file     *writer.File
+	async    *writer.Async
+}
+
+var levels = []logger.Level{
+	logger.TraceLevel,
+	logger.DebugLevel,
+	logger.InfoLevel,
+	logger.WarnLevel,
+	logger.ErrorLevel,
+	logger.FatalLevel,
+	logger.PanicLevel,
+}
+
+func New(config *logger.Config) (*Logger, error) {
+	var (
+		output     io.Writer
+		async      *writer.Async
+		err        error
+		timeFormat = time.RFC3339
+	)
+
+	// set slog config
+	if config.AtomicLevel == nil {
+		config.AtomicLevel = logger.NewAtomicLevel(config.Level)
+	}
+	if config.TimeFormat != "" {
+		timeFormat = config.TimeFormat
+	}
+	options := &slog.HandlerOptions{
+		AddSource:   config.WithCaller,
+		Level:       leveler{config.AtomicLevel},
+		ReplaceAttr: replaceAttr(timeFormat),
+	}
+
+	// set output log
+	output = os.Stderr
+	useJSON := config.UseJSON && !config.IsDevelopment
+
+	file, err := config.OpenLogFile()
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		useJSON = true
+		output = file
+	}
+
+	if config.UseMultiWriters {
+		// without log file, log is printed into the console only
+		output = os.Stdout
+		if file != nil {
+			output = io.MultiWriter(file, os.Stdout)
+		}
+	}
+
+	initialAttrs := make([]slog.Attr, 0)
+	if config.AppName != "" {
+		initialAttrs = append(initialAttrs, slog.String("app", config.AppName))
+	}
+	if config.Environment != "" {
+		initialAttrs = append(initialAttrs, slog.String("env", config.Environment))
+	}
+
+	newHandler := func(w io.Writer) slog.Handler {
+		var handler slog.Handler = slog.NewTextHandler(w, options)
+		if useJSON {
+			handler = slog.NewJSONHandler(w, options)
+		}
+		if len(initialAttrs) > 0 {
+			handler = handler.WithAttrs(initialAttrs)
+		}
+		return handler
+	}
+
+	// every level shares one handler, unless async writer needs the level of each log
+	handlers := make(map[logger.Level]slog.Handler, len(levels))
+	handler := newHandler(output)
+	for _, level := range levels {
+		handlers[level] = handler
+	}
+
+	if config.Async != nil {
+		async, err = writer.NewAsync(output, *config.Async)
+		if err != nil {
+			return nil, err
+		}
+		for _, level := range levels {
+			handlers[level] = newHandler(async.Level(int(level)))
+		}
+	}
+
+	return &Logger{
+		handlers: handlers,
+		config:   config,
+		file:     file,
+		async:    async,
+	}, nil
+}
+
+// Sync flushes buffered logs into every sink
+func (l *Logger) Sync() error {
+	var err error
+	if l.async != nil {
+		err = l.async.Sync()
+	}
+	if l.file != nil {
+		err = errors.Join(err, l.file.Sync())
+	}
+	return err
+}
+
+// Close writes queued logs and releases the log file
+func (l *Logger) Close() error {
+	err := l.Sync()
+	if l.async != nil {
+		err = errors.Join(err, l.async.Close())
+	}
+	if l.file != nil {
+		err = errors.Join(err, l.file.Close())
+	}
+	return err
+}
+
+// Dropped returns number of logs dropped by async writer
+func (l *Logger) Dropped() uint64 {
+	if l.async != nil {
+		return l.async.Dropped()
+	}
+	return 0
+}
+
+func setLevel(level logger.Level) slog.Level {
+	switch level {
+	case logger.TraceLevel:
+		return LevelTrace
+	case logger.DebugLevel:
+		return slog.LevelDebug
+	case logger.InfoLevel:
+		return slog.LevelInfo
+	case logger.WarnLevel:
+		return slog.LevelWarn
+	case logger.ErrorLevel:
+		return slog.LevelError
+	case logger.FatalLevel:
+		return LevelFatal
+	case logger.PanicLevel:
+		return LevelPanic
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// leveler reads slog minimum level from go-log atomic level
+type leveler struct {
+	level *logger.AtomicLevel
+}
+
+func (l leveler) Level() slog.Level {
+	return setLevel(l.level.Level())
+}
+
+// replaceAttr renames slog built-in keys to match go-log field names
+func replaceAttr(timeFormat string) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+
+		switch a.Key {
+		case slog.TimeKey:
+			return slog.String("timestamp", a.Value.Time().Format(timeFormat))
+		case slog.LevelKey:
+			level, _ := a.Value.Any().(slog.Level)
+			switch {
+			case level >= LevelPanic:
+				return slog.String("level", "panic")
+			case level >= LevelFatal:
+				return slog.String("level", "fatal")
+			case level <= LevelTrace:
+				return slog.String("level", "trace")
+			}
+			return slog.String("level", strings.ToLower(level.String()))
+		case slog.MessageKey:
+			return slog.Attr{Key: "message", Value: a.Value}
+		case slog.SourceKey:
+			if source, ok := a.Value.Any().(*slog.Source); ok {
+				if source.File == "" {
+					// record without caller, e.g. entry written by Write
+					return slog.Attr{}
+				}
+				return slog.String("line", fmt.Sprintf("%s:%d", source.File, source.Line))
+			}
+			return slog.Attr{Key: "line", Value: a.Value}
+		}
+		return a
+	}
+}
+
+func buildFields(config *logger.Config, field logger.Field, err error) []slog.Attr {
+	attrs := make([]slog.Attr, 0)
+
+	if field.Name != "" {
+		attrs = append(attrs, slog.String(logger.FieldNameLogger, field.Name))
+	}
+
+	if field.RequestID != "" {
+		attrs = append(attrs, slog.String(logger.FieldNameRequestID, field.RequestID))
+	}
+
+	if field.TraceID != "" {
+		attrs = append(attrs,
+			slog.String(logger.FieldNameTraceID, field.TraceID),
+			slog.String(logger.FieldNameSpanID, field.SpanID),
+			slog.String(logger.FieldNameTraceFlags, field.TraceFlags),
+		)
+	}
+
+	if field.Source != nil {
+		source := field.Source
+		if config.HasMasking() {
+			source = config.MaskValue(logger.FieldNameSource, source)
+		}
+		if source != nil {
+			attrs = append(attrs, slog.Any(logger.FieldNameSource, source))
+		}
+	}
+
+	if field.UserInfo != nil {
+		userInfo := field.UserInfo
+		if config.HasMasking() {
+			userInfo = config.MaskValue(logger.FieldNameUserInfo, userInfo)
+		}
+		if userInfo != nil {
+			attrs = append(attrs, slog.Any(logger.FieldNameUserInfo, userInfo))
+		}
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", config.RedactError(err).Error()))
+	}
+
+	fields := field.Fields
+	if config.HasMasking() {
+		fields = config.MaskSensitiveData(fields)
+	}
+	for key, value := range fields {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+
+	if len(field.Metadata) > 0 {
+		var metadata interface{} = field.Metadata
+		if config.HasMasking() {
+			metadata = config.MaskValue(logger.FieldNameMetadata, metadata)
+		}
+		if metadata != nil {
+			attrs = append(attrs, slog.Any(logger.FieldNameMetadata, metadata))
+		}
+	}
+
+	return attrs
+}
+
+// buildStack returns stack trace of the log when it reaches the stack level
+func buildStack(config *logger.Config, level logger.Level, err error, skip int) (slog.Attr, bool) {
+	if !config.WithStack {
+		return slog.Attr{}, false
+	}
+
+	if err != nil && config.StackMarshaller != nil {
+		return slog.Any("stacktrace", config.StackMarshaller(err)), true
+	}
+
+	if level < config.StackLevel {
+		return slog.Attr{}, false
+	}
+
+	var (
+		pcs   [64]uintptr
+		stack strings.Builder
+	)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&stack, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return slog.String("stacktrace", stack.String()), true
+}
+
+func (l *Logger) log(level logger.Level, field logger.Field, err error, message string) {
+	ctx := context.Background()
+	handler := l.handlers[level]
+	if !l.config.Enabled(level, field) {
+		return
+	}
+
+	var pc uintptr
+	callerSkipFrameCount := 4 + l.config.CallerSkip
+	if l.config.WithCaller {
+		pc = field.PC
+		if pc == 0 {
+			var pcs [1]uintptr
+			runtime.Callers(callerSkipFrameCount, pcs[:])
+			pc = pcs[0]
+		}
+	}
+
+	record := slog.NewRecord(time.Now(), setLevel(level), l.config.Redact(message), pc)
+	record.AddAttrs(buildFields(l.config, field, err)...)
+	if stack, ok := buildStack(l.config, level, err, callerSkipFrameCount+1); ok {
+		record.AddAttrs(stack)
+	}
+
+	_ = handler.Handle(ctx, record)
+}
+
+// exit flushes every sink before exiting on fatal
+func (l *Logger) exit() {
+	_ = l.Sync()
+	os.Exit(1)
+}
+
+// raise flushes every sink before panicking, so the log isn't lost when the panic is never recovered
+func (l *Logger) raise(message string) {
+	_ = l.Sync()
+	panic(message)
+}
+
+func (l *Logger) Trace(field logger.Field, err error, message string) {
+	l.log(logger.TraceLevel, field, err, message)
+}
+
+func (l *Logger) Debug(field logger.Field, err error, message string) {
+	l.log(logger.DebugLevel, field, err, message)
+}
+
+func (l *Logger) Info(field logger.Field, err error, message string) {
+	l.log(logger.InfoLevel, field, err, message)
+}
+
+func (l *Logger) Warn(field logger.Field, err error, message string) {
+	l.log(logger.WarnLevel, field, err, message)
+}
+
+func (l *Logger) Error(field logger.Field, err error, message string) {
+	l.log(logger.ErrorLevel, field, err, message)
+}
+
+func (l *Logger) Fatal(field logger.Field, err error, message string) {
+	l.log(logger.FatalLevel, field, err, message)
+	l.exit()
+}
+
+func (l *Logger) Panic(field logger.Field, err error, message string) {
+	l.log(logger.PanicLevel, field, err, message)
+	l.raise(l.config.Redact(message))
+}
+
+func (l *Logger) Tracef(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.TraceLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Debugf(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.DebugLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Infof(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.InfoLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Warnf(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.WarnLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Errorf(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.ErrorLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Fatalf(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.FatalLevel, field, err, fmt.Sprintf(format, args...))
+	l.exit()
+}
+
+func (l *Logger) Panicf(field logger.Field, err error, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	l.log(logger.PanicLevel, field, err, message)
+	l.raise(l.config.Redact(message))
+}
+
+func (l *Logger) Write(entry logger.Entry) {
+	record := slog.NewRecord(entry.Time, setLevel(entry.Level), l.config.Redact(entry.Message), 0)
+	record.AddAttrs(buildFields(l.config, entry.Field, entry.Err)...)
+
+	// handler writes regardless of its leveler, which is only checked by Enabled
+	_ = l.handlers[entry.Level].Handle(context.Background(), record)
+}
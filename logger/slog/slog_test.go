@@ -0,0 +1,193 @@
+package slog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+func newTestLogger(t *testing.T, config logger.Config) (*Logger, func() []map[string]interface{}) {
+	t.Helper()
+
+	config.File = filepath.Join(t.TempDir(), "slog.log")
+	l, err := New(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	return l, func() []map[string]interface{} {
+		t.Helper()
+
+		if err := l.Sync(); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.ReadFile(config.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatalf("invalid log line %q: %v", line, err)
+			}
+			lines = append(lines, m)
+		}
+		return lines
+	}
+}
+
+func TestLoggerKeys(t *testing.T) {
+	l, readLines := newTestLogger(t, logger.Config{
+		AppName:     "app",
+		Environment: "test",
+		TimeFormat:  time.RFC3339Nano,
+		WithCaller:  true,
+	})
+
+	_, _, callerLine, _ := runtime.Caller(0)
+	logThrough(l, logger.Field{RequestID: "req-1", Metadata: map[string]interface{}{"a": 1}}, errors.New("failed"), "hello")
+
+	line := readLines()[0]
+	for key, want := range map[string]interface{}{
+		"level":      "info",
+		"message":    "hello",
+		"app":        "app",
+		"env":        "test",
+		"request_id": "req-1",
+		"error":      "failed",
+	} {
+		if line[key] != want {
+			t.Errorf("%s = %v, want %v", key, line[key], want)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, fmt.Sprint(line["timestamp"])); err != nil {
+		t.Errorf("timestamp = %v, want the time format: %v", line["timestamp"], err)
+	}
+	if caller, _ := line["line"].(string); !strings.HasSuffix(caller, fmt.Sprintf("slog_test.go:%d", callerLine+1)) {
+		t.Errorf("line = %v, want line %d", line["line"], callerLine+1)
+	}
+	for _, key := range []string{"time", "msg", "source"} {
+		if _, ok := line[key]; ok {
+			t.Errorf("slog built-in key %s is not renamed: %v", key, line)
+		}
+	}
+}
+
+func TestLoggerLevelNames(t *testing.T) {
+	l, readLines := newTestLogger(t, logger.Config{Level: logger.TraceLevel})
+
+	l.Trace(logger.Field{}, nil, "trace")
+	l.Debug(logger.Field{}, nil, "debug")
+	l.Warn(logger.Field{}, nil, "warn")
+	l.Write(logger.Entry{Time: time.Now(), Level: logger.FatalLevel, Message: "fatal"})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Panic did not panic")
+			}
+		}()
+		l.Panic(logger.Field{}, nil, "panic")
+	}()
+
+	lines := readLines()
+	want := []string{"trace", "debug", "warn", "fatal", "panic"}
+	if len(lines) != len(want) {
+		t.Fatalf("want %d logs, got %v", len(want), lines)
+	}
+	for i, level := range want {
+		if lines[i]["level"] != level || lines[i]["message"] != level {
+			t.Errorf("log %d = %v %v, want level %s", i, lines[i]["level"], lines[i]["message"], level)
+		}
+	}
+}
+
+// logThrough logs like the log package does, which is the caller expected by the engine
+func logThrough(l *Logger, field logger.Field, err error, message string) {
+	l.Info(field, err, message)
+}
+
+// wrappedLog is a log wrapper of an app, it returns the line calling the log package
+func wrappedLog(l *Logger, message string) int {
+	_, _, line, _ := runtime.Caller(0)
+	logThrough(l, logger.Field{}, nil, message)
+	return line + 1
+}
+
+func TestLoggerCallerSkip(t *testing.T) {
+	for _, skip := range []int{0, 1} {
+		t.Run(fmt.Sprint("skip ", skip), func(t *testing.T) {
+			l, readLines := newTestLogger(t, logger.Config{WithCaller: true, CallerSkip: skip})
+
+			_, _, line, _ := runtime.Caller(0)
+			wrapperLine := wrappedLog(l, "wrapped")
+			pc, _, _, _ := runtime.Caller(0)
+			l.Info(logger.Field{PC: pc}, nil, "known caller")
+
+			// CallerSkip skips the wrapper, so the caller of the wrapper is printed
+			want := line + 1
+			if skip == 0 {
+				want = wrapperLine
+			}
+			lines := readLines()
+			if caller, _ := lines[0]["line"].(string); !strings.HasSuffix(caller, fmt.Sprintf("slog_test.go:%d", want)) {
+				t.Errorf("caller = %q, want line %d", caller, want)
+			}
+			// the program counter of the field wins over the call stack
+			if caller, _ := lines[1]["line"].(string); !strings.HasSuffix(caller, fmt.Sprintf("slog_test.go:%d", line+2)) {
+				t.Errorf("caller of a known pc = %q, want line %d", caller, line+2)
+			}
+		})
+	}
+}
+
+func TestLoggerMasking(t *testing.T) {
+	matcher, err := logger.NewSensitiveMatcher([]string{"password", "metadata.card.number"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	redactor, err := logger.NewRedactor([]logger.Detector{logger.DetectorEmail}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, readLines := newTestLogger(t, logger.Config{SensitiveMatcher: matcher, Redactor: redactor})
+
+	metadata := map[string]interface{}{
+		"password": "secret",
+		"card":     map[string]interface{}{"number": "4111", "brand": "visa"},
+	}
+	l.Info(logger.Field{
+		UserInfo: map[string]interface{}{"name": "john", "password": "hunter2"},
+		Fields:   map[string]interface{}{"password": "bound"},
+		Metadata: metadata,
+	}, errors.New("user a@example.com failed"), "login of a@example.com")
+
+	line := readLines()[0]
+	userInfo, _ := line["user_info"].(map[string]interface{})
+	if userInfo["password"] != "*******" || userInfo["name"] != "john" {
+		t.Errorf("user_info = %v, want password masked", userInfo)
+	}
+	if line["password"] != "*****" {
+		t.Errorf("bound field password = %v, want masked", line["password"])
+	}
+	masked, _ := line["metadata"].(map[string]interface{})
+	card, _ := masked["card"].(map[string]interface{})
+	if masked["password"] != "******" || card["number"] != "****" || card["brand"] != "visa" {
+		t.Errorf("metadata = %v, want password & card number masked", masked)
+	}
+	if line["message"] != "login of [REDACTED:email]" || line["error"] != "user [REDACTED:email] failed" {
+		t.Errorf("message = %v, error = %v, want emails redacted", line["message"], line["error"])
+	}
+	if metadata["password"] != "secret" {
+		t.Error("masking modifies metadata of the caller")
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// maskFixed is fixed-length replacement, so the length of the secret is not revealed
+const maskFixed = "*****"
+
+// MaskStrategy is how a sensitive value is masked, the zero value uses `Config.SensitiveFieldMasker`
+type MaskStrategy struct {
+	mask   func(value string) string
+	remove bool
+}
+
+// MaskFixed replaces the value with `*****` regardless of its length
+func MaskFixed() MaskStrategy {
+	return MaskStrategy{mask: maskFieldFixed}
+}
+
+// MaskKeepLast keeps last n characters of the value (e.g. card number `*****1111`),
+// a value not longer than n is fully masked
+func MaskKeepLast(n int) MaskStrategy {
+	return MaskStrategy{mask: func(value string) string {
+		runes := []rune(value)
+		if n <= 0 || len(runes) <= n {
+			return maskFixed
+		}
+		return maskFixed + string(runes[len(runes)-n:])
+	}}
+}
+
+// MaskHMAC replaces the value with the first 16 bytes of its keyed HMAC-SHA256 hash in hex
+// (e.g. `john@example.com` by key `secret-key` is `hmac:bfdffd5529835960b788d9985c173660`),
+// so the same value correlates across logs without being revealed
+func MaskHMAC(key []byte) MaskStrategy {
+	return MaskStrategy{mask: func(value string) string {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(h.Sum(nil)[:16])
+	}}
+}
+
+// MaskEmail masks local part of the email and keeps its domain (e.g. `*****@example.com`),
+// a value which is not an email is fully masked
+func MaskEmail() MaskStrategy {
+	return MaskStrategy{mask: func(value string) string {
+		at := strings.LastIndexByte(value, '@')
+		if at <= 0 || at == len(value)-1 {
+			return maskFixed
+		}
+		return maskFixed + value[at:]
+	}}
+}
+
+// MaskRemove removes the key from the log entirely
+func MaskRemove() MaskStrategy {
+	return MaskStrategy{remove: true}
+}
+
+// MaskFunc masks the value by custom function
+func MaskFunc(mask func(value string) string) MaskStrategy {
+	return MaskStrategy{mask: mask}
+}
+
+func maskFieldFixed(string) string {
+	return maskFixed
+}
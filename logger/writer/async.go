@@ -0,0 +1,227 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// OverflowPolicy decides what async writer does when its queue is full
+	OverflowPolicy string
+
+	// AsyncConfig of async writer
+	AsyncConfig struct {
+		// QueueSize is maximum number of logs waiting to be written (default: 1024)
+		QueueSize int
+
+		// Overflow is the policy when the queue is full (default: block)
+		Overflow OverflowPolicy
+
+		// DropLevel is the level below which logs are dropped on DropBelowLevel policy,
+		// it uses go-log level value (e.g. int(logger.WarnLevel))
+		DropLevel int
+	}
+)
+
+// list of overflow policy
+const (
+	// Block waits until the queue has room
+	Block OverflowPolicy = "block"
+
+	// DropNewest drops the log being written
+	DropNewest OverflowPolicy = "drop_newest"
+
+	// DropOldest drops the oldest log in the queue to make room
+	DropOldest OverflowPolicy = "drop_oldest"
+
+	// DropBelowLevel drops the log being written when its level is below DropLevel, otherwise waits
+	DropBelowLevel OverflowPolicy = "drop_below_level"
+)
+
+const defaultQueueSize = 1024
+
+type asyncEntry struct {
+	p     []byte
+	flush chan struct{}
+}
+
+// Async is a writer which queues logs and writes them in background,
+// so slow disks don't stall the logging goroutine
+type Async struct {
+	out     io.Writer
+	config  AsyncConfig
+	queue   chan asyncEntry
+	flushes chan chan struct{}
+	dropped atomic.Uint64
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewAsync creates async writer writing into out
+func NewAsync(out io.Writer, config AsyncConfig) (*Async, error) {
+	switch config.Overflow {
+	case "":
+		config.Overflow = Block
+	case Block, DropNewest, DropOldest, DropBelowLevel:
+	default:
+		return nil, fmt.Errorf("unknown overflow policy: %q", config.Overflow)
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+
+	a := &Async{
+		out:     out,
+		config:  config,
+		queue:   make(chan asyncEntry, config.QueueSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+
+	return a, nil
+}
+
+// Write queues the log with the highest level, so it is never dropped by DropBelowLevel policy
+func (a *Async) Write(p []byte) (int, error) {
+	return a.WriteLevel(int(^uint(0)>>1), p)
+}
+
+// WriteLevel queues the log, level is go-log level value of the log
+func (a *Async) WriteLevel(level int, p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		// background writer is gone, write directly instead of losing the log
+		return a.out.Write(p)
+	}
+
+	// copy since the engines reuse the buffer once Write returns
+	entry := asyncEntry{p: append([]byte(nil), p...)}
+
+	switch a.config.Overflow {
+	case DropNewest:
+		select {
+		case a.queue <- entry:
+		default:
+			a.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case a.queue <- entry:
+				return len(p), nil
+			default:
+			}
+			select {
+			case oldest := <-a.queue:
+				if oldest.flush != nil {
+					// never drop a pending Sync, logs queued before it are already taken by the background writer,
+					// so hand it over directly rather than queueing it again, which blocks when other writers fill the room
+					go func() { a.flushes <- oldest.flush }()
+					continue
+				}
+				a.dropped.Add(1)
+			default:
+			}
+		}
+	case DropBelowLevel:
+		if level < a.config.DropLevel {
+			select {
+			case a.queue <- entry:
+			default:
+				a.dropped.Add(1)
+			}
+			break
+		}
+		a.queue <- entry
+	default:
+		a.queue <- entry
+	}
+
+	return len(p), nil
+}
+
+// Level returns writer which writes every log with the given level
+func (a *Async) Level(level int) io.Writer {
+	return asyncLevelWriter{async: a, level: level}
+}
+
+// Dropped returns number of logs dropped by the overflow policy
+func (a *Async) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Sync waits until every queued log is written, then syncs the underlying writer
+func (a *Async) Sync() error {
+	a.mu.RLock()
+	if !a.closed {
+		a.drain()
+	}
+	a.mu.RUnlock()
+
+	if syncer, ok := a.out.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close writes every queued log then stops the background writer,
+// the underlying writer is not closed
+func (a *Async) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return nil
+	}
+	a.drain()
+	a.closed = true
+	close(a.done)
+	return nil
+}
+
+// drain waits until logs queued before it are written, a.mu must be held
+func (a *Async) drain() {
+	flush := make(chan struct{})
+	a.queue <- asyncEntry{flush: flush}
+	<-flush
+}
+
+func (a *Async) run() {
+	for {
+		select {
+		case <-a.done:
+			return
+		case flush := <-a.flushes:
+			close(flush)
+		case entry := <-a.queue:
+			if entry.flush != nil {
+				close(entry.flush)
+				continue
+			}
+			if _, err := a.out.Write(entry.p); err != nil {
+				fmt.Fprintf(os.Stderr, "go-log: failed to write log: %v\n", err)
+			}
+		}
+	}
+}
+
+type asyncLevelWriter struct {
+	async *Async
+	level int
+}
+
+func (w asyncLevelWriter) Write(p []byte) (int, error) {
+	return w.async.WriteLevel(w.level, p)
+}
+
+func (w asyncLevelWriter) Sync() error {
+	return w.async.Sync()
+}
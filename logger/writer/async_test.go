@@ -0,0 +1,255 @@
+package writer
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter records written logs, its first Write waits until the gate is opened
+type gateWriter struct {
+	mu      sync.Mutex
+	logs    []string
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() {
+		close(w.started)
+		<-w.gate
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.logs = append(w.logs, string(p))
+	return len(p), nil
+}
+
+func (w *gateWriter) written() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.logs, ",")
+}
+
+// newFullAsync returns async writer whose background writer is stuck on log "1" and whose queue holds "2" & "3"
+func newFullAsync(t *testing.T, config AsyncConfig) (*Async, *gateWriter) {
+	t.Helper()
+
+	out := newGateWriter()
+	config.QueueSize = 2
+	a, err := NewAsync(out, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = a.Close() })
+
+	if _, err := a.Write([]byte("1")); err != nil {
+		t.Fatal(err)
+	}
+	<-out.started
+	for _, p := range []string{"2", "3"} {
+		if _, err := a.Write([]byte(p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return a, out
+}
+
+// assertBlocked asserts write doesn't return until the gate of out is opened
+func assertBlocked(t *testing.T, out *gateWriter, write func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		write()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("write returns while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(out.gate)
+	<-done
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      AsyncConfig
+		overflow    func(t *testing.T, a *Async, out *gateWriter)
+		wantWritten string
+		wantDropped uint64
+	}{
+		{
+			name:   "block",
+			config: AsyncConfig{Overflow: Block},
+			overflow: func(t *testing.T, a *Async, out *gateWriter) {
+				assertBlocked(t, out, func() { _, _ = a.Write([]byte("4")) })
+			},
+			wantWritten: "1,2,3,4",
+		},
+		{
+			name:   "default is block",
+			config: AsyncConfig{},
+			overflow: func(t *testing.T, a *Async, out *gateWriter) {
+				assertBlocked(t, out, func() { _, _ = a.Write([]byte("4")) })
+			},
+			wantWritten: "1,2,3,4",
+		},
+		{
+			name:   "drop newest",
+			config: AsyncConfig{Overflow: DropNewest},
+			overflow: func(t *testing.T, a *Async, out *gateWriter) {
+				_, _ = a.Write([]byte("4"))
+				_, _ = a.Write([]byte("5"))
+				close(out.gate)
+			},
+			wantWritten: "1,2,3",
+			wantDropped: 2,
+		},
+		{
+			name:   "drop oldest",
+			config: AsyncConfig{Overflow: DropOldest},
+			overflow: func(t *testing.T, a *Async, out *gateWriter) {
+				_, _ = a.Write([]byte("4"))
+				_, _ = a.Write([]byte("5"))
+				close(out.gate)
+			},
+			wantWritten: "1,4,5",
+			wantDropped: 2,
+		},
+		{
+			name:   "drop below level",
+			config: AsyncConfig{Overflow: DropBelowLevel, DropLevel: 3},
+			overflow: func(t *testing.T, a *Async, out *gateWriter) {
+				_, _ = a.WriteLevel(2, []byte("4"))
+				_, _ = a.Level(1).Write([]byte("5"))
+				assertBlocked(t, out, func() { _, _ = a.WriteLevel(3, []byte("6")) })
+			},
+			wantWritten: "1,2,3,6",
+			wantDropped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, out := newFullAsync(t, tt.config)
+			tt.overflow(t, a, out)
+
+			if err := a.Sync(); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.written(); got != tt.wantWritten {
+				t.Errorf("written = %s, want %s", got, tt.wantWritten)
+			}
+			if got := a.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestAsyncDropOldestKeepsSync(t *testing.T) {
+	out := newGateWriter()
+	a, err := NewAsync(out, AsyncConfig{QueueSize: 1, Overflow: DropOldest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	// the background writer is stuck on "1" while the queue holds a pending Sync
+	_, _ = a.Write([]byte("1"))
+	<-out.started
+	synced := make(chan error)
+	go func() { synced <- a.Sync() }()
+	deadline := time.Now().Add(time.Second)
+	for len(a.queue) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Sync is not queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = a.Write([]byte("2"))
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("Write waits for the background writer while the oldest in the queue is a pending Sync")
+	}
+
+	select {
+	case <-synced:
+		t.Fatal("Sync returns before the log being written is done")
+	default:
+	}
+	close(out.gate)
+	select {
+	case err := <-synced:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Sync is lost by the drop oldest policy")
+	}
+	if err := a.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.written(); got != "1,2" {
+		t.Errorf("written = %s, want 1,2", got)
+	}
+	if got := a.Dropped(); got != 0 {
+		t.Errorf("Dropped() = %d, want the pending Sync not counted", got)
+	}
+}
+
+func TestAsyncCloseDrains(t *testing.T) {
+	out := newGateWriter()
+	close(out.gate)
+	a, err := NewAsync(out, AsyncConfig{QueueSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	for i := 0; i < 100; i++ {
+		want = append(want, "log")
+		_, _ = a.Write([]byte("log"))
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.written(); got != strings.Join(want, ",") {
+		t.Errorf("written %d logs on Close, want 100", strings.Count(got, "log"))
+	}
+
+	// once closed, logs are written directly & a second Close is a no-op
+	_, _ = a.Write([]byte("after close"))
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.written(); !strings.HasSuffix(got, ",after close") {
+		t.Errorf("written = %s, want the log after Close", got)
+	}
+}
+
+func TestNewAsyncInvalidOverflow(t *testing.T) {
+	if _, err := NewAsync(newGateWriter(), AsyncConfig{Overflow: "drop_all"}); err == nil {
+		t.Error("NewAsync() of an unknown overflow policy returns no error")
+	}
+}
@@ -0,0 +1,432 @@
+package writer
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// Interval of time-based rotation
+	Interval string
+
+	// Rotation config of log file
+	Rotation struct {
+		// MaxSize is maximum size in megabytes before the file gets rotated, 0 disables size rotation
+		MaxSize int
+
+		// Every is time-based rotation interval, empty disables time rotation
+		Every Interval
+
+		// MaxBackups is maximum number of rotated files to retain, 0 retains all
+		MaxBackups int
+
+		// MaxAge is maximum days to retain rotated files, 0 retains all
+		MaxAge int
+
+		// Compress is a toggle to gzip rotated files
+		Compress bool
+
+		// ReopenOnSIGHUP is a toggle to reopen the file on SIGHUP, for logrotate compatibility
+		ReopenOnSIGHUP bool
+	}
+)
+
+// list of rotation interval
+const (
+	Hourly Interval = "hourly"
+	Daily  Interval = "daily"
+)
+
+const (
+	megabyte           = 1024 * 1024
+	backupTimeFormat   = "2006-01-02T15-04-05.000"
+	backupSeqSeparator = "_"
+	compressSuffix     = ".gz"
+)
+
+// File is log file writer which rotates the file based on its size and time
+type File struct {
+	mu       sync.Mutex
+	path     string
+	rotation Rotation
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+
+	mill    chan struct{}
+	sighup  chan os.Signal
+	done    chan struct{}
+	closeWg sync.WaitGroup
+}
+
+// NewFile opens log file or generates it if not exist
+func NewFile(path string, rotation Rotation) (*File, error) {
+	switch rotation.Every {
+	case "", Hourly, Daily:
+	default:
+		return nil, fmt.Errorf("unknown rotation interval: %q", rotation.Every)
+	}
+
+	f := &File{
+		path:     path,
+		rotation: rotation,
+		mill:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	// backups left by earlier runs are cleaned up right away, not on the first rotation
+	f.mill <- struct{}{}
+	f.closeWg.Add(1)
+	go f.runMill()
+
+	if rotation.ReopenOnSIGHUP {
+		f.sighup = make(chan os.Signal, 1)
+		signal.Notify(f.sighup, syscall.SIGHUP)
+		f.closeWg.Add(1)
+		go f.runSIGHUP()
+	}
+
+	return f, nil
+}
+
+// Write writes log into the file, rotating it first when it reaches max size or rotation time
+func (f *File) Write(p []byte) (int, error) {
+	if f == nil {
+		return 0, os.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync commits written logs into the disk
+func (f *File) Sync() error {
+	if f == nil {
+		return os.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and stops background rotation jobs
+func (f *File) Close() error {
+	if f == nil {
+		return os.ErrInvalid
+	}
+
+	f.mu.Lock()
+	if f.file == nil {
+		f.mu.Unlock()
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	f.mu.Unlock()
+
+	if f.sighup != nil {
+		signal.Stop(f.sighup)
+	}
+	close(f.done)
+	f.closeWg.Wait()
+	return err
+}
+
+// Rotate rotates the file right away
+func (f *File) Rotate() error {
+	if f == nil {
+		return os.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Reopen closes and reopens the file, use it after the file is moved by external tools like logrotate
+func (f *File) Reopen() error {
+	if f == nil {
+		return os.ErrInvalid
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *File) open() error {
+	err := os.MkdirAll(filepath.Dir(f.path), 0755)
+	if err != nil && err != os.ErrExist {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.rotateAt = f.nextRotation(info.ModTime())
+	return nil
+}
+
+func (f *File) shouldRotate(writeSize int64) bool {
+	if f.rotation.MaxSize > 0 && f.size > 0 && f.size+writeSize > int64(f.rotation.MaxSize)*megabyte {
+		return true
+	}
+	return !f.rotateAt.IsZero() && !time.Now().Before(f.rotateAt)
+}
+
+func (f *File) nextRotation(from time.Time) time.Time {
+	switch f.rotation.Every {
+	case Hourly:
+		return from.Truncate(time.Hour).Add(time.Hour)
+	case Daily:
+		year, month, day := from.Date()
+		return time.Date(year, month, day+1, 0, 0, 0, 0, from.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// rotate renames current file into a backup then opens a new file, f.mu must be held
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.path, f.backupName(time.Now())); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	// clean up backups in background, a pending signal is enough
+	select {
+	case f.mill <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// backupName returns unused name of backup rotated at the time, e.g. `app-2024-07-23T14-52-00.000.log`,
+// a counter is added when rotated more than once in the same millisecond, e.g. `app-2024-07-23T14-52-00.000_1.log`
+func (f *File) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	name := prefix + t.Format(backupTimeFormat)
+	for seq := 0; ; seq++ {
+		backup := filepath.Join(dir, name+ext)
+		if seq > 0 {
+			backup = filepath.Join(dir, name+backupSeqSeparator+strconv.Itoa(seq)+ext)
+		}
+		if !exists(backup) && !exists(backup+compressSuffix) {
+			return backup
+		}
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return !os.IsNotExist(err)
+}
+
+// nameParts splits the path, e.g. `/var/log/app.log` into `/var/log`, `app-` & `.log`
+func (f *File) nameParts() (dir, prefix, ext string) {
+	name := filepath.Base(f.path)
+	ext = filepath.Ext(name)
+	return filepath.Dir(f.path), strings.TrimSuffix(name, ext) + "-", ext
+}
+
+func (f *File) runSIGHUP() {
+	defer f.closeWg.Done()
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-f.sighup:
+			if err := f.Reopen(); err != nil && err != os.ErrClosed {
+				fmt.Fprintf(os.Stderr, "go-log: failed to reopen log file: %v\n", err)
+			}
+		}
+	}
+}
+
+func (f *File) runMill() {
+	defer f.closeWg.Done()
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-f.mill:
+			if err := f.millBackups(); err != nil {
+				fmt.Fprintf(os.Stderr, "go-log: failed to clean up rotated log files: %v\n", err)
+			}
+		}
+	}
+}
+
+type backup struct {
+	path string
+	time time.Time
+	// seq orders backups rotated in the same millisecond, see backupName
+	seq int
+}
+
+// millBackups removes backups exceeding MaxBackups or MaxAge then compresses the remaining ones
+func (f *File) millBackups() error {
+	if f.rotation.MaxBackups == 0 && f.rotation.MaxAge == 0 && !f.rotation.Compress {
+		return nil
+	}
+
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	var (
+		cutoff = time.Now().AddDate(0, 0, -f.rotation.MaxAge)
+		errs   []string
+	)
+	for i, b := range backups {
+		remove := f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups
+		remove = remove || (f.rotation.MaxAge > 0 && b.time.Before(cutoff))
+
+		switch {
+		case remove:
+			err = os.Remove(b.path)
+		case f.rotation.Compress && !strings.HasSuffix(b.path, compressSuffix):
+			err = compress(b.path)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// backups returns rotated files sorted from the newest
+func (f *File) backups() ([]backup, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]backup, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		timestamp := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		timestamp = strings.TrimPrefix(timestamp, prefix)
+		var seq int
+		if i := strings.LastIndex(timestamp, backupSeqSeparator); i >= 0 {
+			if seq, err = strconv.Atoi(timestamp[i+1:]); err != nil {
+				continue
+			}
+			timestamp = timestamp[:i]
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), time: t, seq: seq})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].seq > backups[j].seq
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups, nil
+}
+
+// compress gzips the file then removes the original one
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + compressSuffix)
+		return err
+	}
+
+	_ = src.Close()
+	return os.Remove(path)
+}
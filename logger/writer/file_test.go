@@ -0,0 +1,267 @@
+package writer
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNilFile(t *testing.T) {
+	var f *File
+
+	if _, err := f.Write([]byte("log\n")); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("Write() error = %v, want %v", err, os.ErrInvalid)
+	}
+	for name, method := range map[string]func() error{
+		"Sync":   f.Sync,
+		"Close":  f.Close,
+		"Rotate": f.Rotate,
+		"Reopen": f.Reopen,
+	} {
+		if err := method(); !errors.Is(err, os.ErrInvalid) {
+			t.Errorf("%s() error = %v, want %v", name, err, os.ErrInvalid)
+		}
+	}
+}
+
+func newTestFile(t *testing.T, rotation Rotation) (*File, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewFile(path, rotation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f, path
+}
+
+func write(t *testing.T, f *File, s string) {
+	t.Helper()
+
+	if _, err := f.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// waitBackups waits until the mill leaves n backups, it returns them from the newest
+func waitBackups(t *testing.T, f *File, n int) []backup {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		backups, err := f.backups()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(backups) == n {
+			return backups
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backups = %v, want %d", backups, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFileSizeRotation(t *testing.T) {
+	f, path := newTestFile(t, Rotation{MaxSize: 1})
+
+	// every write rotates the previous one, mostly within the same millisecond
+	chunk := strings.Repeat("x", 700*1024-1) + "\n"
+	for i := 0; i < 5; i++ {
+		write(t, f, chunk)
+	}
+
+	backups := waitBackups(t, f, 4)
+	for _, b := range backups {
+		if got := readString(t, b.path); got != chunk {
+			t.Errorf("backup %s has %d bytes, want %d", b.path, len(got), len(chunk))
+		}
+	}
+	if got := readString(t, path); got != chunk {
+		t.Errorf("current file has %d bytes, want %d", len(got), len(chunk))
+	}
+}
+
+func TestBackupNameCollision(t *testing.T) {
+	f, _ := newTestFile(t, Rotation{})
+	now := time.Now()
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		name := f.backupName(now)
+		if err := os.WriteFile(name, []byte(strconv.Itoa(i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if names[0] == names[1] || names[1] == names[2] {
+		t.Fatalf("backup names collide: %v", names)
+	}
+
+	// backups of the same millisecond are ordered by their counter, the newest first
+	backups := waitBackups(t, f, 3)
+	for i, b := range backups {
+		if want := names[2-i]; b.path != want {
+			t.Errorf("backup %d = %s, want %s", i, b.path, want)
+		}
+	}
+}
+
+func TestFileTimeRotation(t *testing.T) {
+	f, path := newTestFile(t, Rotation{Every: Hourly})
+
+	write(t, f, "before\n")
+	f.mu.Lock()
+	if want := time.Now().Truncate(time.Hour).Add(time.Hour); !f.rotateAt.Equal(want) {
+		t.Errorf("rotateAt = %s, want %s", f.rotateAt, want)
+	}
+	f.rotateAt = time.Now()
+	f.mu.Unlock()
+	write(t, f, "after\n")
+
+	backups := waitBackups(t, f, 1)
+	if got := readString(t, backups[0].path); got != "before\n" {
+		t.Errorf("backup = %q, want before", got)
+	}
+	if got := readString(t, path); got != "after\n" {
+		t.Errorf("current file = %q, want after", got)
+	}
+	if !f.rotateAt.After(time.Now()) {
+		t.Errorf("next rotation %s is not in the future", f.rotateAt)
+	}
+}
+
+func TestFileMaxBackups(t *testing.T) {
+	f, _ := newTestFile(t, Rotation{MaxBackups: 2})
+
+	for i := 0; i < 5; i++ {
+		write(t, f, strconv.Itoa(i))
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups := waitBackups(t, f, 2)
+	for i, want := range []string{"4", "3"} {
+		if got := readString(t, backups[i].path); got != want {
+			t.Errorf("backup %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestFileMaxAgeAtStartup(t *testing.T) {
+	dir := t.TempDir()
+	oldBackup := filepath.Join(dir, "app-"+time.Now().AddDate(0, 0, -10).Format(backupTimeFormat)+".log")
+	newBackup := filepath.Join(dir, "app-"+time.Now().Add(-time.Hour).Format(backupTimeFormat)+".log")
+	other := filepath.Join(dir, "app-notes.log")
+	for _, path := range []string{oldBackup, newBackup, other} {
+		if err := os.WriteFile(path, []byte("log\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no rotation happens, backups of earlier runs are still cleaned up
+	f, err := NewFile(filepath.Join(dir, "app.log"), Rotation{MaxAge: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	backups := waitBackups(t, f, 1)
+	if backups[0].path != newBackup {
+		t.Errorf("kept backup = %s, want %s", backups[0].path, newBackup)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("file which is not a backup is removed: %v", err)
+	}
+}
+
+func TestFileCompress(t *testing.T) {
+	f, _ := newTestFile(t, Rotation{Compress: true})
+
+	write(t, f, "compressed log\n")
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var backups []backup
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		backups = waitBackups(t, f, 1)
+		if strings.HasSuffix(backups[0].path, compressSuffix) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backup %s is not compressed", backups[0].path)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	file, err := os.Open(backups[0].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "compressed log\n" {
+		t.Errorf("decompressed backup = %q, want the rotated log", got)
+	}
+}
+
+func TestFileReopen(t *testing.T) {
+	f, path := newTestFile(t, Rotation{})
+
+	write(t, f, "before move\n")
+	// logrotate moves the file away, writes still go to the moved file until reopened
+	moved := path + ".1"
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+	write(t, f, "still moved\n")
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	write(t, f, "after reopen\n")
+
+	if got := readString(t, moved); got != "before move\nstill moved\n" {
+		t.Errorf("moved file = %q", got)
+	}
+	if got := readString(t, path); got != "after reopen\n" {
+		t.Errorf("reopened file = %q", got)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Reopen() after Close error = %v, want %v", err, os.ErrClosed)
+	}
+	if _, err := f.Write([]byte("closed\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write() after Close error = %v, want %v", err, os.ErrClosed)
+	}
+}
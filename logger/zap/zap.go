@@ -1,17 +1,30 @@
 package zap
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
 
 	"github.com/rizanw/go-log/logger"
+	"github.com/rizanw/go-log/logger/writer"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// traceLevel is zap level for trace log since zap starts at debug
+const traceLevel = zapcore.DebugLevel - 1
+
 type Logger struct {
 	logger *zap.Logger
+	// unleveled is logger ignoring minimum level, for logs with minimum level override
+	unleveled *zap.Logger
+	// nop is logger writing nothing, for logs below minimum level override
+	nop    *zap.Logger
 	config *logger.Config
+	file   *writer.File
+	async  *writer.Async
 }
 
 func New(config *logger.Config) (*Logger, error) {
@@ -22,8 +35,16 @@ func New(config *logger.Config) (*Logger, error) {
 	)
 
 	// set zap config
+	if config.AtomicLevel == nil {
+		config.AtomicLevel = logger.NewAtomicLevel(config.Level)
+	}
+	// compared in go-log levels, zap ranks panic below fatal
+	levelEnabler := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+		return toLevel(level) >= config.AtomicLevel.Level()
+	})
 	configEncoder.MessageKey = "message"
 	configEncoder.LevelKey = "level"
+	configEncoder.EncodeLevel = encodeLevel
 	configEncoder.TimeKey = "timestamp"
 	configEncoder.EncodeTime = zapcore.RFC3339TimeEncoder
 	if config.TimeFormat != "" {
@@ -31,14 +52,14 @@ func New(config *logger.Config) (*Logger, error) {
 	}
 	configEncoder.StacktraceKey = "stacktrace"
 	configEncoder.CallerKey = "line"
-	callerSkipFrameCount := 2 + config.CallerSkip
+	callerSkipFrameCount := 3 + config.CallerSkip
 	if !config.WithCaller {
 		configEncoder.CallerKey = zapcore.OmitKey
 	}
 
 	// set output log
 	zapEncoder := zapcore.NewJSONEncoder(configEncoder)
-	writer := zapcore.AddSync(os.Stderr)
+	output := zapcore.AddSync(console{os.Stderr})
 
 	if config.IsDevelopment {
 		zapEncoder = zapcore.NewConsoleEncoder(configEncoder)
@@ -53,7 +74,7 @@ func New(config *logger.Config) (*Logger, error) {
 	}
 	if file != nil {
 		zapEncoder = zapcore.NewJSONEncoder(configEncoder)
-		writer = zapcore.AddSync(file)
+		output = zapcore.AddSync(file)
 	}
 
 	initialFields := make([]zap.Field, 0)
@@ -64,14 +85,31 @@ func New(config *logger.Config) (*Logger, error) {
 		initialFields = append(initialFields, zap.String("env", config.Environment))
 	}
 
-	zapCore := zapcore.NewCore(zapEncoder, writer, setLevel(config.Level))
-	if config.UseMultiWriters {
+	if config.UseMultiWriters && file == nil {
+		// without log file, log is printed into the console only
+		output = zapcore.AddSync(console{os.Stdout})
+	}
+
+	zapCore := zapcore.NewCore(zapEncoder, output, levelEnabler)
+	if config.UseMultiWriters && file != nil {
 		zapCore = zapcore.NewTee(
-			zapcore.NewCore(zapEncoder, zapcore.Lock(file), setLevel(config.Level)),
-			zapcore.NewCore(zapEncoder, zapcore.Lock(os.Stdout), setLevel(config.Level)),
+			zapcore.NewCore(zapEncoder, zapcore.Lock(file), levelEnabler),
+			zapcore.NewCore(zapEncoder, zapcore.Lock(console{os.Stdout}), levelEnabler),
 		)
 	}
 
+	var async *writer.Async
+	if config.Async != nil {
+		if config.UseMultiWriters && file != nil {
+			output = zapcore.NewMultiWriteSyncer(zapcore.Lock(file), zapcore.Lock(console{os.Stdout}))
+		}
+		async, err = writer.NewAsync(output, *config.Async)
+		if err != nil {
+			return nil, err
+		}
+		zapCore = &asyncCore{LevelEnabler: levelEnabler, encoder: zapEncoder, async: async}
+	}
+
 	zapLogger = zap.New(zapCore,
 		zap.Fields(initialFields...),
 	)
@@ -81,18 +119,157 @@ func New(config *logger.Config) (*Logger, error) {
 	}
 
 	if config.WithStack {
-		zapLogger = zapLogger.WithOptions(zap.AddStacktrace(setLevel(config.StackLevel)))
+		zapLogger = zapLogger.WithOptions(zap.AddStacktrace(zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+			return toLevel(level) >= config.StackLevel
+		})))
 	}
 
-	defer zapLogger.Sync()
-	return &Logger{
-		logger: zapLogger,
+	l := &Logger{
 		config: config,
-	}, nil
+		file:   file,
+		async:  async,
+	}
+	l.logger = zapLogger.WithOptions(zap.WithFatalHook(fatalHook{l}))
+	l.unleveled = l.logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return unleveledCore{core}
+	}))
+	// nop keeps fatal hook so fatal logs below the override still flush & exit
+	l.nop = l.logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return zapcore.NewNopCore()
+	}))
+	return l, nil
+}
+
+// Sync flushes buffered logs into every sink
+func (l *Logger) Sync() error {
+	return l.logger.Sync()
+}
+
+// Close flushes buffered logs and releases the log file
+func (l *Logger) Close() error {
+	err := l.Sync()
+	if l.async != nil {
+		err = errors.Join(err, l.async.Close())
+	}
+	if l.file != nil {
+		err = errors.Join(err, l.file.Close())
+	}
+	return err
+}
+
+// fatalHook flushes every sink before exiting on fatal
+type fatalHook struct {
+	logger *Logger
+}
+
+func (h fatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	_ = h.logger.Sync()
+	os.Exit(1)
+}
+
+// console is os.Stderr or os.Stdout writer, sync is skipped since terminals & pipes don't support fsync
+type console struct {
+	io.Writer
+}
+
+func (console) Sync() error {
+	return nil
+}
+
+// Dropped returns number of logs dropped by async writer
+func (l *Logger) Dropped() uint64 {
+	if l.async != nil {
+		return l.async.Dropped()
+	}
+	return 0
+}
+
+// asyncCore is zapcore.Core writing encoded logs into async writer along with their level
+type asyncCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	async   *writer.Async
+}
+
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return &asyncCore{LevelEnabler: c.LevelEnabler, encoder: encoder, async: c.async}
+}
+
+func (c *asyncCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *asyncCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	_, err = c.async.WriteLevel(int(toLevel(entry.Level)), buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+
+	// write queued logs before zap exits on fatal
+	if entry.Level > zapcore.ErrorLevel {
+		return c.Sync()
+	}
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	return c.async.Sync()
+}
+
+// unleveledCore is zapcore.Core writing every level, the minimum level is checked by leveled instead
+type unleveledCore struct {
+	zapcore.Core
+}
+
+func (c unleveledCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c unleveledCore) With(fields []zapcore.Field) zapcore.Core {
+	return unleveledCore{c.Core.With(fields)}
+}
+
+func (c unleveledCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+// leveled returns zap logger honoring minimum level override of the field
+func (l *Logger) leveled(level logger.Level, field logger.Field) *zap.Logger {
+	switch {
+	case field.MinLevel == nil:
+		return l.logger
+	case l.config.Enabled(level, field):
+		return l.unleveled
+	default:
+		return l.nop
+	}
+}
+
+// encodeLevel encodes level in lower case like zapcore.LowercaseLevelEncoder, including trace
+func encodeLevel(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	if level == traceLevel {
+		enc.AppendString("trace")
+		return
+	}
+	zapcore.LowercaseLevelEncoder(level, enc)
 }
 
 func setLevel(level logger.Level) zapcore.Level {
 	switch level {
+	case logger.TraceLevel:
+		return traceLevel
 	case logger.DebugLevel:
 		return zap.DebugLevel
 	case logger.InfoLevel:
@@ -103,91 +280,175 @@ func setLevel(level logger.Level) zapcore.Level {
 		return zap.ErrorLevel
 	case logger.FatalLevel:
 		return zap.FatalLevel
+	case logger.PanicLevel:
+		return zap.PanicLevel
 	default:
 		return zap.DebugLevel
 	}
 }
 
+func toLevel(level zapcore.Level) logger.Level {
+	switch level {
+	case traceLevel:
+		return logger.TraceLevel
+	case zap.DebugLevel:
+		return logger.DebugLevel
+	case zap.InfoLevel:
+		return logger.InfoLevel
+	case zap.WarnLevel:
+		return logger.WarnLevel
+	case zap.ErrorLevel:
+		return logger.ErrorLevel
+	case zap.FatalLevel:
+		return logger.FatalLevel
+	case zap.DPanicLevel, zap.PanicLevel:
+		return logger.PanicLevel
+	}
+	if level < traceLevel {
+		return logger.TraceLevel
+	}
+	return logger.ErrorLevel
+}
+
 func buildFields(cfg *logger.Config, field logger.Field, err error) []zap.Field {
 	zapFields := make([]zap.Field, 0)
 
+	if field.Name != "" {
+		zapFields = append(zapFields, zap.String(logger.FieldNameLogger, field.Name))
+	}
+
 	if field.RequestID != "" {
 		zapFields = append(zapFields, zap.String(logger.FieldNameRequestID, field.RequestID))
 	}
 
+	if field.TraceID != "" {
+		zapFields = append(zapFields,
+			zap.String(logger.FieldNameTraceID, field.TraceID),
+			zap.String(logger.FieldNameSpanID, field.SpanID),
+			zap.String(logger.FieldNameTraceFlags, field.TraceFlags),
+		)
+	}
+
 	if field.Source != nil {
-		zapFields = append(zapFields, zap.Any(logger.FieldNameSource, field.Source))
+		source := field.Source
+		if cfg.HasMasking() {
+			source = cfg.MaskValue(logger.FieldNameSource, source)
+		}
+		if source != nil {
+			zapFields = append(zapFields, zap.Any(logger.FieldNameSource, source))
+		}
 	}
 
 	if field.UserInfo != nil {
 		userInfo := field.UserInfo
-		if len(cfg.SensitiveFields) > 0 {
-			if ui, ok := userInfo.(map[string]interface{}); ok {
-				cfg.MaskSensitiveData(ui)
-				userInfo = ui
-			}
+		if cfg.HasMasking() {
+			userInfo = cfg.MaskValue(logger.FieldNameUserInfo, userInfo)
+		}
+		if userInfo != nil {
+			zapFields = append(zapFields, zap.Any(logger.FieldNameUserInfo, userInfo))
 		}
-		zapFields = append(zapFields, zap.Any(logger.FieldNameUserInfo, userInfo))
 	}
 
 	if err != nil {
-		zapFields = append(zapFields, zap.Error(err))
+		zapFields = append(zapFields, zap.Error(cfg.RedactError(err)))
 	}
 
-	if len(cfg.SensitiveFields) > 0 {
-		cfg.MaskSensitiveData(field.Fields)
+	fields := field.Fields
+	if cfg.HasMasking() {
+		fields = cfg.MaskSensitiveData(fields)
 	}
-	for key, value := range field.Fields {
+	for key, value := range fields {
 		zapFields = append(zapFields, zap.Any(key, value))
 	}
 
 	if len(field.Metadata) > 0 {
-		metadata := field.Metadata
-		if len(cfg.SensitiveFields) > 0 {
-			cfg.MaskSensitiveData(metadata)
+		var metadata interface{} = field.Metadata
+		if cfg.HasMasking() {
+			metadata = cfg.MaskValue(logger.FieldNameMetadata, metadata)
+		}
+		if metadata != nil {
+			zapFields = append(zapFields, zap.Any(logger.FieldNameMetadata, metadata))
 		}
-		zapFields = append(zapFields, zap.Any(logger.FieldNameMetadata, metadata))
 	}
 
 	return zapFields
 }
 
+// log writes the log through zap, fatal & panic logs exit & panic by their zap hooks even when disabled
+func (l *Logger) log(level logger.Level, field logger.Field, err error, message string) {
+	ce := l.leveled(level, field).Check(setLevel(level), l.config.Redact(message))
+	if ce == nil {
+		return
+	}
+	if field.PC != 0 && ce.Caller.Defined {
+		frame, _ := runtime.CallersFrames([]uintptr{field.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{Defined: true, PC: frame.PC, File: frame.File, Line: frame.Line, Function: frame.Function}
+	}
+	ce.Write(buildFields(l.config, field, err)...)
+}
+
+func (l *Logger) Trace(field logger.Field, err error, message string) {
+	l.log(logger.TraceLevel, field, err, message)
+}
+
 func (l *Logger) Debug(field logger.Field, err error, message string) {
-	l.logger.Debug(message, buildFields(l.config, field, err)...)
+	l.log(logger.DebugLevel, field, err, message)
 }
 
 func (l *Logger) Info(field logger.Field, err error, message string) {
-	l.logger.Info(message, buildFields(l.config, field, err)...)
+	l.log(logger.InfoLevel, field, err, message)
 }
 
 func (l *Logger) Warn(field logger.Field, err error, message string) {
-	l.logger.Warn(message, buildFields(l.config, field, err)...)
+	l.log(logger.WarnLevel, field, err, message)
 }
 
 func (l *Logger) Error(field logger.Field, err error, message string) {
-	l.logger.Error(message, buildFields(l.config, field, err)...)
+	l.log(logger.ErrorLevel, field, err, message)
 }
 
 func (l *Logger) Fatal(field logger.Field, err error, message string) {
-	l.logger.Fatal(message, buildFields(l.config, field, err)...)
+	l.log(logger.FatalLevel, field, err, message)
+}
+
+func (l *Logger) Panic(field logger.Field, err error, message string) {
+	l.log(logger.PanicLevel, field, err, message)
+}
+
+func (l *Logger) Tracef(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.TraceLevel, field, err, fmt.Sprintf(format, args...))
 }
 
 func (l *Logger) Debugf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Debug(fmt.Sprintf(format, args...), buildFields(l.config, field, err)...)
+	l.log(logger.DebugLevel, field, err, fmt.Sprintf(format, args...))
 }
 
 func (l *Logger) Infof(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Info(fmt.Sprintf(format, args...), buildFields(l.config, field, err)...)
+	l.log(logger.InfoLevel, field, err, fmt.Sprintf(format, args...))
 }
 
 func (l *Logger) Warnf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Warn(fmt.Sprintf(format, args...), buildFields(l.config, field, err)...)
+	l.log(logger.WarnLevel, field, err, fmt.Sprintf(format, args...))
 }
 
 func (l *Logger) Errorf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Error(fmt.Sprintf(format, args...), buildFields(l.config, field, err)...)
+	l.log(logger.ErrorLevel, field, err, fmt.Sprintf(format, args...))
 }
 
 func (l *Logger) Fatalf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Fatal(fmt.Sprintf(format, args...), buildFields(l.config, field, err)...)
+	l.log(logger.FatalLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Panicf(field logger.Field, err error, format string, args ...interface{}) {
+	l.log(logger.PanicLevel, field, err, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Write(entry logger.Entry) {
+	zapEntry := zapcore.Entry{
+		Level:   setLevel(entry.Level),
+		Time:    entry.Time,
+		Message: l.config.Redact(entry.Message),
+	}
+	// core writes regardless of its level enabler
+	_ = l.logger.Core().Write(zapEntry, buildFields(l.config, entry.Field, entry.Err))
 }
@@ -1,54 +1,72 @@
 package zerolog
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"time"
 
 	"github.com/rizanw/go-log/logger"
+	"github.com/rizanw/go-log/logger/writer"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/pkgerrors"
 )
 
 type Logger struct {
 	logger *zerolog.Logger
-	config *logger.Config
+	// replay is logger without caller to write entries logged earlier
+	replay          *zerolog.Logger
+	config          *logger.Config
+	timeFormat      string
+	file            *writer.File
+	async           *writer.Async
+	stackMarshaller func(err error) interface{}
+}
+
+func init() {
+	// field names are zerolog globals, so set them once instead of on every New
+	// to keep logging race free while the logger is being replaced
+	zerolog.TimestampFieldName = "timestamp"
+	zerolog.CallerFieldName = "line"
+	zerolog.ErrorStackFieldName = "stacktrace"
 }
 
 func New(config *logger.Config) (*Logger, error) {
 	var (
-		zeroLogger zerolog.Logger
-		err        error
-		writer     io.Writer
-		timeFormat string = time.RFC3339
+		zeroLogger      zerolog.Logger
+		err             error
+		output          io.Writer
+		timeFormat      string = time.RFC3339
+		stackMarshaller func(err error) interface{}
 	)
 
 	// set zerolog config
+	if config.AtomicLevel == nil {
+		config.AtomicLevel = logger.NewAtomicLevel(config.Level)
+	}
 	if config.TimeFormat != "" {
 		timeFormat = config.TimeFormat
 	}
-	zerolog.TimestampFieldName = "timestamp"
-	zerolog.TimeFieldFormat = timeFormat
-	zerolog.CallerFieldName = "line"
 	callerSkipFrameCount := 4 + config.CallerSkip
-	zerolog.ErrorStackFieldName = "stacktrace"
 	if config.WithStack {
-		zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
+		stackMarshaller = pkgerrors.MarshalStack
 		if config.StackMarshaller != nil {
-			zerolog.ErrorStackMarshaler = config.StackMarshaller
+			stackMarshaller = config.StackMarshaller
 		}
 	}
 
 	// set output log
-	writer = os.Stderr
+	output = os.Stderr
 
 	if config.IsDevelopment && config.UseColor {
-		writer = zerolog.ConsoleWriter{
+		output = zerolog.ConsoleWriter{
 			Out:        os.Stdout,
 			TimeFormat: timeFormat,
 		}
 	} else if !config.UseJSON {
-		writer = zerolog.ConsoleWriter{
+		output = zerolog.ConsoleWriter{
 			Out:        os.Stderr,
 			NoColor:    true,
 			TimeFormat: timeFormat,
@@ -60,36 +78,127 @@ func New(config *logger.Config) (*Logger, error) {
 		return nil, err
 	}
 	if file != nil {
-		writer = file
+		output = file
 	}
 
 	if config.IsDevelopment {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+		zerolog.SetGlobalLevel(zerolog.TraceLevel)
 	}
 
 	if config.UseMultiWriters {
-		writer = zerolog.MultiLevelWriter(file, os.Stdout)
+		// without log file, log is printed into the console only
+		output = os.Stdout
+		if file != nil {
+			output = zerolog.MultiLevelWriter(file, os.Stdout)
+		}
 	}
 
-	zeroLogger = zerolog.New(writer).With().Timestamp().Logger().Level(setLevel(config.Level))
+	var async *writer.Async
+	if config.Async != nil {
+		async, err = writer.NewAsync(output, *config.Async)
+		if err != nil {
+			return nil, err
+		}
+		output = asyncWriter{async}
+	}
+
+	// minimum level is checked against config.Enabled on every log, see event
+	// timestamp, app & env lead every log, see start
+	zeroLogger = zerolog.New(output)
+	replayLogger := zeroLogger
 	if config.WithCaller {
 		zeroLogger = zeroLogger.With().CallerWithSkipFrameCount(callerSkipFrameCount).Logger()
 	}
-	if config.AppName != "" {
-		zeroLogger = zeroLogger.With().Str("app", config.AppName).Logger()
-	}
-	if config.Environment != "" {
-		zeroLogger = zeroLogger.With().Str("env", config.Environment).Logger()
-	}
 
 	return &Logger{
-		logger: &zeroLogger,
-		config: config,
+		logger:          &zeroLogger,
+		replay:          &replayLogger,
+		config:          config,
+		timeFormat:      timeFormat,
+		file:            file,
+		async:           async,
+		stackMarshaller: stackMarshaller,
 	}, nil
 }
 
-func setLevel(level logger.Level) zerolog.Level {
+// Sync flushes buffered logs into every sink
+func (l *Logger) Sync() error {
+	var err error
+	if l.async != nil {
+		err = l.async.Sync()
+	}
+	if l.file != nil {
+		err = errors.Join(err, l.file.Sync())
+	}
+	return err
+}
+
+// Close writes queued logs and releases the log file
+func (l *Logger) Close() error {
+	err := l.Sync()
+	if l.async != nil {
+		err = errors.Join(err, l.async.Close())
+	}
+	if l.file != nil {
+		err = errors.Join(err, l.file.Close())
+	}
+	return err
+}
+
+// exit flushes every sink before exiting on fatal
+func (l *Logger) exit() {
+	_ = l.Sync()
+	os.Exit(1)
+}
+
+// raise flushes every sink before panicking, so the log isn't lost when the panic is never recovered
+func (l *Logger) raise(message string) {
+	_ = l.Sync()
+	panic(message)
+}
+
+// Dropped returns number of logs dropped by async writer
+func (l *Logger) Dropped() uint64 {
+	if l.async != nil {
+		return l.async.Dropped()
+	}
+	return 0
+}
+
+// asyncWriter passes zerolog level into async writer
+type asyncWriter struct {
+	*writer.Async
+}
+
+func (w asyncWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	return w.Async.WriteLevel(int(toLevel(level)), p)
+}
+
+func toLevel(level zerolog.Level) logger.Level {
+	switch level {
+	case zerolog.TraceLevel:
+		return logger.TraceLevel
+	case zerolog.DebugLevel:
+		return logger.DebugLevel
+	case zerolog.InfoLevel:
+		return logger.InfoLevel
+	case zerolog.WarnLevel:
+		return logger.WarnLevel
+	case zerolog.ErrorLevel:
+		return logger.ErrorLevel
+	case zerolog.FatalLevel:
+		return logger.FatalLevel
+	case zerolog.PanicLevel:
+		return logger.PanicLevel
+	default:
+		return logger.DebugLevel
+	}
+}
+
+func fromLevel(level logger.Level) zerolog.Level {
 	switch level {
+	case logger.TraceLevel:
+		return zerolog.TraceLevel
 	case logger.DebugLevel:
 		return zerolog.DebugLevel
 	case logger.InfoLevel:
@@ -98,89 +207,201 @@ func setLevel(level logger.Level) zerolog.Level {
 		return zerolog.WarnLevel
 	case logger.ErrorLevel:
 		return zerolog.ErrorLevel
-	case logger.FatalLevel:
-		return zerolog.FatalLevel
+	case logger.PanicLevel:
+		return zerolog.PanicLevel
 	default:
-		return zerolog.DebugLevel
+		return zerolog.FatalLevel
 	}
 }
 
+// start writes the fields leading every log right after the level, the timestamp is formatted per logger
+// instead of using global zerolog.TimeFieldFormat, and written here since zerolog hooks run after the fields
+func (l *Logger) start(e *zerolog.Event, timestamp time.Time) *zerolog.Event {
+	if e == nil {
+		return nil
+	}
+	e = e.Str(zerolog.TimestampFieldName, timestamp.Format(l.timeFormat))
+	if l.config.AppName != "" {
+		e = e.Str("app", l.config.AppName)
+	}
+	if l.config.Environment != "" {
+		e = e.Str("env", l.config.Environment)
+	}
+	return e
+}
+
 func buildFields(config *logger.Config, field logger.Field) map[string]interface{} {
 	mapFields := make(map[string]interface{})
 
+	if field.Name != "" {
+		mapFields[logger.FieldNameLogger] = field.Name
+	}
+
 	if field.RequestID != "" {
 		mapFields[logger.FieldNameRequestID] = field.RequestID
 	}
 
+	if field.TraceID != "" {
+		mapFields[logger.FieldNameTraceID] = field.TraceID
+		mapFields[logger.FieldNameSpanID] = field.SpanID
+		mapFields[logger.FieldNameTraceFlags] = field.TraceFlags
+	}
+
 	if field.Source != nil {
-		mapFields[logger.FieldNameSource] = field.Source
+		source := field.Source
+		if config.HasMasking() {
+			source = config.MaskValue(logger.FieldNameSource, source)
+		}
+		if source != nil {
+			mapFields[logger.FieldNameSource] = source
+		}
 	}
 
 	if field.UserInfo != nil {
 		userInfo := field.UserInfo
-		if len(config.SensitiveFields) > 0 {
-			if ui, ok := userInfo.(map[string]interface{}); ok {
-				config.MaskSensitiveData(ui)
-				userInfo = ui
-			}
+		if config.HasMasking() {
+			userInfo = config.MaskValue(logger.FieldNameUserInfo, userInfo)
+		}
+		if userInfo != nil {
+			mapFields[logger.FieldNameUserInfo] = userInfo
 		}
-		mapFields[logger.FieldNameUserInfo] = userInfo
 	}
 
-	if len(config.SensitiveFields) > 0 {
-		config.MaskSensitiveData(field.Fields)
+	fields := field.Fields
+	if config.HasMasking() {
+		fields = config.MaskSensitiveData(fields)
 	}
-	for key, value := range field.Fields {
+	for key, value := range fields {
 		mapFields[key] = value
 	}
 
 	if len(field.Metadata) > 0 {
-		metadata := field.Metadata
-		if len(config.SensitiveFields) > 0 {
-			config.MaskSensitiveData(metadata)
+		var metadata interface{} = field.Metadata
+		if config.HasMasking() {
+			metadata = config.MaskValue(logger.FieldNameMetadata, metadata)
+		}
+		if metadata != nil {
+			mapFields[logger.FieldNameMetadata] = metadata
 		}
-		mapFields[logger.FieldNameMetadata] = metadata
 	}
 
 	return mapFields
 }
 
+// event creates zerolog event, it returns nil (no-op event) when the level is disabled
+func (l *Logger) event(level logger.Level, field logger.Field) *zerolog.Event {
+	if !l.config.Enabled(level, field) {
+		return nil
+	}
+
+	zeroLogger := l.logger
+	if field.PC != 0 && l.config.WithCaller {
+		// the caller is known upfront, see withFields
+		zeroLogger = l.replay
+	}
+
+	var e *zerolog.Event
+	switch level {
+	case logger.TraceLevel:
+		e = zeroLogger.Trace()
+	case logger.DebugLevel:
+		e = zeroLogger.Debug()
+	case logger.InfoLevel:
+		e = zeroLogger.Info()
+	case logger.WarnLevel:
+		e = zeroLogger.Warn()
+	case logger.ErrorLevel:
+		e = zeroLogger.Error()
+	case logger.PanicLevel:
+		// zerolog Panic doesn't panic when the event is disabled, so panic is called after the log instead
+		e = zeroLogger.WithLevel(zerolog.PanicLevel)
+	default:
+		// zerolog Fatal exits without flushing our sinks, so exit is called after the log instead
+		e = zeroLogger.WithLevel(zerolog.FatalLevel)
+	}
+	return l.start(e, time.Now())
+}
+
+// withFields adds go-log fields, stack trace & error into the event
+func (l *Logger) withFields(e *zerolog.Event, field logger.Field, err error) *zerolog.Event {
+	if e == nil {
+		return nil
+	}
+
+	e = e.Fields(buildFields(l.config, field))
+	if err != nil && l.stackMarshaller != nil {
+		e = e.Interface(zerolog.ErrorStackFieldName, l.stackMarshaller(err))
+	}
+	if field.PC != 0 && l.config.WithCaller {
+		frame, _ := runtime.CallersFrames([]uintptr{field.PC}).Next()
+		e = e.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(frame.PC, frame.File, frame.Line))
+	}
+	return e.Err(l.config.RedactError(err))
+}
+
+func (l *Logger) Trace(field logger.Field, err error, message string) {
+	l.withFields(l.event(logger.TraceLevel, field), field, err).Msg(l.config.Redact(message))
+}
+
 func (l *Logger) Debug(field logger.Field, err error, message string) {
-	l.logger.Debug().Fields(buildFields(l.config, field)).Stack().Err(err).Msg(message)
+	l.withFields(l.event(logger.DebugLevel, field), field, err).Msg(l.config.Redact(message))
 }
 
 func (l *Logger) Info(field logger.Field, err error, message string) {
-	l.logger.Info().Fields(buildFields(l.config, field)).Stack().Err(err).Msg(message)
+	l.withFields(l.event(logger.InfoLevel, field), field, err).Msg(l.config.Redact(message))
 }
 
 func (l *Logger) Warn(field logger.Field, err error, message string) {
-	l.logger.Warn().Fields(buildFields(l.config, field)).Stack().Err(err).Msg(message)
+	l.withFields(l.event(logger.WarnLevel, field), field, err).Msg(l.config.Redact(message))
 }
 
 func (l *Logger) Error(field logger.Field, err error, message string) {
-	l.logger.Error().Fields(buildFields(l.config, field)).Stack().Err(err).Msg(message)
+	l.withFields(l.event(logger.ErrorLevel, field), field, err).Msg(l.config.Redact(message))
 }
 
 func (l *Logger) Fatal(field logger.Field, err error, message string) {
-	l.logger.Fatal().Fields(buildFields(l.config, field)).Stack().Err(err).Msg(message)
+	l.withFields(l.event(logger.FatalLevel, field), field, err).Msg(l.config.Redact(message))
+	l.exit()
+}
+
+func (l *Logger) Panic(field logger.Field, err error, message string) {
+	message = l.config.Redact(message)
+	l.withFields(l.event(logger.PanicLevel, field), field, err).Msg(message)
+	l.raise(message)
+}
+
+func (l *Logger) Tracef(field logger.Field, err error, format string, args ...interface{}) {
+	l.withFields(l.event(logger.TraceLevel, field), field, err).Msg(l.config.Redact(fmt.Sprintf(format, args...)))
 }
 
 func (l *Logger) Debugf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Debug().Fields(buildFields(l.config, field)).Stack().Err(err).Msgf(format, args...)
+	l.withFields(l.event(logger.DebugLevel, field), field, err).Msg(l.config.Redact(fmt.Sprintf(format, args...)))
 }
 
 func (l *Logger) Infof(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Info().Fields(buildFields(l.config, field)).Stack().Err(err).Msgf(format, args...)
+	l.withFields(l.event(logger.InfoLevel, field), field, err).Msg(l.config.Redact(fmt.Sprintf(format, args...)))
 }
 
 func (l *Logger) Warnf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Warn().Fields(buildFields(l.config, field)).Stack().Err(err).Msgf(format, args...)
+	l.withFields(l.event(logger.WarnLevel, field), field, err).Msg(l.config.Redact(fmt.Sprintf(format, args...)))
 }
 
 func (l *Logger) Errorf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Error().Fields(buildFields(l.config, field)).Stack().Err(err).Msgf(format, args...)
+	l.withFields(l.event(logger.ErrorLevel, field), field, err).Msg(l.config.Redact(fmt.Sprintf(format, args...)))
 }
 
 func (l *Logger) Fatalf(field logger.Field, err error, format string, args ...interface{}) {
-	l.logger.Fatal().Fields(buildFields(l.config, field)).Stack().Err(err).Msgf(format, args...)
+	l.withFields(l.event(logger.FatalLevel, field), field, err).Msg(l.config.Redact(fmt.Sprintf(format, args...)))
+	l.exit()
+}
+
+func (l *Logger) Panicf(field logger.Field, err error, format string, args ...interface{}) {
+	message := l.config.Redact(fmt.Sprintf(format, args...))
+	l.withFields(l.event(logger.PanicLevel, field), field, err).Msg(message)
+	l.raise(message)
+}
+
+func (l *Logger) Write(entry logger.Entry) {
+	e := l.start(l.replay.WithLevel(fromLevel(entry.Level)), entry.Time)
+	l.withFields(e, entry.Field, entry.Err).Msg(l.config.Redact(entry.Message))
 }
@@ -0,0 +1,54 @@
+package zerolog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+func TestLoggerTimestampFirst(t *testing.T) {
+	config := logger.Config{
+		AppName:     "app",
+		Environment: "test",
+		UseJSON:     true,
+		TimeFormat:  time.RFC3339Nano,
+		WithCaller:  true,
+		File:        filepath.Join(t.TempDir(), "zerolog.log"),
+	}
+	l, err := New(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Info(logger.Field{RequestID: "req-1"}, errors.New("failed"), "now")
+	earlier := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	l.Write(logger.Entry{Time: earlier, Level: logger.WarnLevel, Field: logger.Field{RequestID: "req-1"}, Message: "earlier"})
+
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(config.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 logs, got %q", lines)
+	}
+
+	prefix := `{"level":"info","timestamp":"`
+	if !strings.HasPrefix(lines[0], prefix) || !strings.Contains(lines[0], `","app":"app","env":"test",`) {
+		t.Errorf("log = %s, want it to start with %s...app & env", lines[0], prefix)
+	}
+	// entries logged earlier keep their own time
+	prefix = `{"level":"warn","timestamp":"` + earlier.Format(time.RFC3339Nano) + `","app":"app","env":"test",`
+	if !strings.HasPrefix(lines[1], prefix) {
+		t.Errorf("replayed log = %s, want it to start with %s", lines[1], prefix)
+	}
+}
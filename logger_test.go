@@ -0,0 +1,226 @@
+package log
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// spyLogger counts Sync & Close of the wrapped engine
+type spyLogger struct {
+	Logger
+	syncs  atomic.Int32
+	closes atomic.Int32
+}
+
+func (s *spyLogger) Sync() error {
+	s.syncs.Add(1)
+	return s.Logger.Sync()
+}
+
+func (s *spyLogger) Close() error {
+	s.closes.Add(1)
+	// engines flush on Close, the spy makes it visible
+	s.syncs.Add(1)
+	return s.Logger.Close()
+}
+
+// spyEngines makes SetConfig wrap every engine it creates in a spy, it returns the spies created so far
+func spyEngines(t *testing.T) func() []*spyLogger {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		spies []*spyLogger
+	)
+	t.Cleanup(func() { newEngine = NewLogger })
+	newEngine = func(config logger.Config, engine logger.Engine) (Logger, error) {
+		l, err := NewLogger(config, engine)
+		if err != nil {
+			return nil, err
+		}
+		spy := &spyLogger{Logger: l}
+		mu.Lock()
+		spies = append(spies, spy)
+		mu.Unlock()
+		return spy, nil
+	}
+
+	return func() []*spyLogger {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*spyLogger(nil), spies...)
+	}
+}
+
+func countLines(t *testing.T, paths []string, marker string) int {
+	t.Helper()
+
+	count := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if strings.Contains(scanner.Text(), marker) {
+				count++
+			}
+		}
+		_ = f.Close()
+	}
+	return count
+}
+
+func TestSetConfigConcurrentSwap(t *testing.T) {
+	var (
+		engines = []Engine{Zerolog, Zap, Slog}
+		dir     = t.TempDir()
+		paths   []string
+		spies   = spyEngines(t)
+		written atomic.Int64
+		stop    = make(chan struct{})
+		wg      sync.WaitGroup
+	)
+
+	swap := func(i int) {
+		path := filepath.Join(dir, fmt.Sprintf("swap-%d.log", i))
+		paths = append(paths, path)
+
+		err := SetConfig(&Config{Engine: engines[i%len(engines)], FilePath: path, UseJSON: true, UseAsync: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active := spies(); active[len(active)-1].closes.Load() != 0 {
+			t.Fatalf("swap %d: active engine is closed", i)
+		}
+	}
+
+	// workers start once logs go to the files being counted
+	swap(0)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := SetCtxRequestID(context.Background())
+			for n := 0; ; n++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				Infof(ctx, nil, KV{"worker": i}, "swap-test %d", n)
+				With(KV{"child": true}).Warn(ctx, nil, nil, "swap-test child")
+				written.Add(2)
+			}
+		}(i)
+	}
+
+	for i := 1; i < 30; i++ {
+		time.Sleep(2 * time.Millisecond)
+		swap(i)
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	close(stop)
+	wg.Wait()
+	// retires the last engine & restores the default logger for other tests
+	if err := SetConfig(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	all := spies()
+	if len(all) != 31 {
+		t.Fatalf("created %d engines, want 31", len(all))
+	}
+	for i, spy := range all[:30] {
+		if spy.closes.Load() != 1 {
+			t.Errorf("engine %d: closed %d times, want 1", i, spy.closes.Load())
+		}
+		if spy.syncs.Load() == 0 {
+			t.Errorf("engine %d: never synced", i)
+		}
+	}
+
+	if got, want := countLines(t, paths, "swap-test"), int(written.Load()); got != want {
+		t.Errorf("written lines = %d, want %d", got, want)
+	}
+}
+
+func TestRetireWaitsInflightLogs(t *testing.T) {
+	defer func(timeout time.Duration) { retireTimeout = timeout }(retireTimeout)
+	retireTimeout = 50 * time.Millisecond
+
+	spies := spyEngines(t)
+	if err := SetConfig(&Config{Engine: Zap, FilePath: filepath.Join(t.TempDir(), "retire.log"), UseAsync: true}); err != nil {
+		t.Fatal(err)
+	}
+	spy := spies()[0]
+
+	// a log blocked in the engine, e.g. on a stalled disk
+	inflight := acquire()
+
+	start := time.Now()
+	if err := SetConfig(nil); !errors.Is(err, ErrRetireTimeout) {
+		t.Fatalf("SetConfig with a blocked in-flight log = %v, want ErrRetireTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("SetConfig waited %s, want about %s", elapsed, retireTimeout)
+	}
+	if spy.closes.Load() != 0 {
+		t.Fatal("engine closed while a log is in flight")
+	}
+
+	inflight.release()
+	deadline := time.Now().Add(time.Second)
+	for spy.closes.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("engine not closed once the in-flight log finished")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSetConfigMultiWritersWithoutFile(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			stdout := os.Stdout
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// engines take the console writer on SetConfig
+			os.Stdout = w
+			err = SetConfig(&Config{Engine: engine, UseMultiWriters: true, UseJSON: true})
+			os.Stdout = stdout
+			if err != nil {
+				t.Fatalf("SetConfig with multi writers & no file path = %v, want console only", err)
+			}
+
+			Info(context.Background(), nil, nil, "multi writers test")
+			if err := SetConfig(nil); err != nil {
+				t.Fatal(err)
+			}
+			w.Close()
+			out, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), "multi writers test") {
+				t.Errorf("stdout = %q, want the log", out)
+			}
+		})
+	}
+}
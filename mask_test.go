@@ -0,0 +1,128 @@
+package log
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type maskAccount struct {
+	Username string            `json:"username"`
+	Password string            `json:"password"`
+	Card     *maskCard         `json:"card"`
+	Tags     []string          `json:"tags"`
+	Extra    map[string]string `json:"extra"`
+}
+
+type maskCard struct {
+	Number string `json:"number"`
+	Holder string `json:"holder"`
+}
+
+func TestMaskLeavesInputUntouched(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata func() KV
+		userInfo func() interface{}
+	}{
+		{
+			name: "nested maps",
+			metadata: func() KV {
+				return KV{"request": map[string]interface{}{
+					"password": "secret-pass",
+					"card":     map[string]interface{}{"number": "4111111111111111", "password": "secret-pass"},
+				}}
+			},
+		},
+		{
+			name: "slices",
+			metadata: func() KV {
+				return KV{
+					"items": []interface{}{
+						map[string]interface{}{"password": "secret-pass"},
+						[]map[string]interface{}{{"password": "secret-pass", "name": "a"}},
+					},
+					"names": []string{"john", "jane"},
+				}
+			},
+		},
+		{
+			name: "structs",
+			metadata: func() KV {
+				return KV{
+					"account": maskAccount{
+						Username: "john",
+						Password: "secret-pass",
+						Card:     &maskCard{Number: "4111111111111111", Holder: "john"},
+						Tags:     []string{"a", "b"},
+						Extra:    map[string]string{"password": "secret-pass"},
+					},
+					"accounts": []*maskAccount{{Username: "jane", Password: "secret-pass"}},
+				}
+			},
+		},
+		{
+			name: "ctx user_info map",
+			userInfo: func() interface{} {
+				return KV{"id": 1, "password": "secret-pass", "profile": map[string]interface{}{"password": "secret-pass"}}
+			},
+		},
+		{
+			name: "ctx user_info struct",
+			userInfo: func() interface{} {
+				return &maskAccount{Username: "john", Password: "secret-pass", Extra: map[string]string{"password": "secret-pass"}}
+			},
+		},
+	}
+
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		for _, tt := range tests {
+			t.Run(engine.String()+"/"+tt.name, func(t *testing.T) {
+				path := filepath.Join(t.TempDir(), "mask.log")
+				err := SetConfig(&Config{Engine: engine, FilePath: path, UseJSON: true, MaskSensitiveData: []string{"password"}})
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer SetConfig(nil)
+
+				var metadata, wantMetadata KV
+				if tt.metadata != nil {
+					metadata, wantMetadata = tt.metadata(), tt.metadata()
+				}
+				ctx := context.Background()
+				var userInfo, wantUserInfo interface{}
+				if tt.userInfo != nil {
+					userInfo, wantUserInfo = tt.userInfo(), tt.userInfo()
+					ctx = SetCtxUserInfo(ctx, userInfo)
+				}
+
+				Info(ctx, nil, metadata, "mask test")
+				With(KV{"child": true}).Info(ctx, nil, metadata, "mask test child")
+				if err := Sync(); err != nil {
+					t.Fatal(err)
+				}
+
+				if !reflect.DeepEqual(metadata, wantMetadata) {
+					t.Errorf("metadata modified by masking:\n got %#v\nwant %#v", metadata, wantMetadata)
+				}
+				if !reflect.DeepEqual(userInfo, wantUserInfo) {
+					t.Errorf("user info modified by masking:\n got %#v\nwant %#v", userInfo, wantUserInfo)
+				}
+
+				out, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strings.Count(string(out), "mask test") != 2 {
+					t.Fatalf("want 2 logs, got %s", out)
+				}
+				if strings.Contains(string(out), "secret-pass") {
+					t.Errorf("sensitive value is not masked: %s", out)
+				}
+			})
+		}
+	}
+}
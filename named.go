@@ -0,0 +1,71 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// rnamed is minimum log level per logger name shared by every logger created by SetConfig
+var rnamed = logger.NewNamedLevels(nil)
+
+// Named creates a child logger of a component (e.g. `db`), printed as `logger` in every log,
+// its minimum level is resolved from NamedLevels
+func Named(name string) *ChildLogger {
+	return (&ChildLogger{}).Named(name)
+}
+
+// Named creates a child logger carrying the parent fields, named by the parent name plus the given name,
+// e.g. `db.pool` for `pool` of `db` which inherits the level of `db` unless it has its own
+func (c *ChildLogger) Named(name string) *ChildLogger {
+	child := c.With(nil)
+	child.name = name
+	if c.name != "" {
+		child.name = c.name + "." + name
+	}
+	return child
+}
+
+// SetNamedLevel changes minimum log level of the logger name at runtime, rules of the other names are kept
+func SetNamedLevel(name string, level Level) {
+	rnamed.SetLevel(name, level)
+}
+
+// RemoveNamedLevel removes minimum log level of the logger name, so it inherits its parent or the global level
+func RemoveNamedLevel(name string) {
+	rnamed.RemoveLevel(name)
+}
+
+// SetNamedLevels replaces minimum log levels of every logger name at runtime
+func SetNamedLevels(levels map[string]Level) {
+	rnamed.SetLevels(levels)
+}
+
+// GetNamedLevels returns current minimum log levels per logger name
+func GetNamedLevels() map[string]Level {
+	return rnamed.Levels()
+}
+
+// ParseNamedLevels parses comma separated `name=level` rules, e.g. `db=warn,http=info,cache=debug`
+func ParseNamedLevels(rules string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, levelName, ok := strings.Cut(rule, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid named level rule %q, expected name=level", rule)
+		}
+		level, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return nil, fmt.Errorf("invalid named level rule %q: %w", rule, err)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
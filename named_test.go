@@ -0,0 +1,100 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseNamedLevels(t *testing.T) {
+	tests := []struct {
+		rules   string
+		want    map[string]Level
+		wantErr bool
+	}{
+		{rules: "", want: map[string]Level{}},
+		{rules: "db=warn", want: map[string]Level{"db": WarnLevel}},
+		{rules: " db = warn , db.pool=debug,, http=ERROR ", want: map[string]Level{"db": WarnLevel, "db.pool": DebugLevel, "http": ErrorLevel}},
+		{rules: "db", wantErr: true},
+		{rules: "=warn", wantErr: true},
+		{rules: "db=loud", wantErr: true},
+		{rules: "db=warn,http", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseNamedLevels(tt.rules)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseNamedLevels(%q) error = %v, wantErr %v", tt.rules, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseNamedLevels(%q) = %v, want %v", tt.rules, got, tt.want)
+		}
+	}
+}
+
+func TestNamedLevelResolution(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "named.log")
+			err := SetConfig(&Config{
+				Engine:      engine,
+				FilePath:    path,
+				UseJSON:     true,
+				Level:       InfoLevel,
+				NamedLevels: map[string]Level{"db": WarnLevel, "cache": DebugLevel},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer SetConfig(nil)
+
+			ctx := context.Background()
+			db, pool, cache, other := Named("db"), Named("db").Named("pool"), Named("cache"), Named("http")
+
+			db.Info(ctx, nil, nil, "db info")
+			db.Warn(ctx, nil, nil, "db warn")
+			// db.pool inherits db
+			pool.Info(ctx, nil, nil, "pool info")
+			pool.Error(ctx, nil, nil, "pool error")
+			// a named level may be lower than the global one
+			cache.Debug(ctx, nil, nil, "cache debug")
+			// a name without rule follows the global level
+			other.Debug(ctx, nil, nil, "http debug")
+			other.Info(ctx, nil, nil, "http info")
+			// the level of the context overrides the named one
+			db.Debug(SetCtxLevel(ctx, DebugLevel), nil, nil, "db debug of a request")
+
+			// rules change at runtime, for loggers already created too
+			SetNamedLevel("db.pool", DebugLevel)
+			pool.Debug(ctx, nil, nil, "pool debug")
+			db.Info(ctx, nil, nil, "db info again")
+			RemoveNamedLevel("db.pool")
+			pool.Info(ctx, nil, nil, "pool info after remove")
+			SetNamedLevels(map[string]Level{"http": ErrorLevel})
+			other.Warn(ctx, nil, nil, "http warn")
+			db.Info(ctx, nil, nil, "db info after replace")
+
+			var got []string
+			for _, line := range readLines(t, path) {
+				got = append(got, line["logger"].(string)+": "+line["message"].(string))
+			}
+			want := []string{
+				"db: db warn",
+				"db.pool: pool error",
+				"cache: cache debug",
+				"http: http info",
+				"db: db debug of a request",
+				"db.pool: pool debug",
+				"db: db info after replace",
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("written logs:\n%q\nwant:\n%q", got, want)
+			}
+			if levels := GetNamedLevels(); !reflect.DeepEqual(levels, map[string]Level{"http": ErrorLevel}) {
+				t.Errorf("GetNamedLevels() = %v", levels)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRedactErrorKeepsVerbose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "redact.log")
+	if err := SetConfig(&Config{Engine: Zap, FilePath: path, UseJSON: true, RedactDetectors: []RedactDetector{RedactEmail}}); err != nil {
+		t.Fatal(err)
+	}
+	defer SetConfig(nil)
+
+	Error(context.Background(), errors.New("user a@example.com not found"), nil, "lookup a@example.com failed")
+
+	out := readFile(t, path)
+	if strings.Contains(out, "a@example.com") {
+		t.Errorf("secret is not redacted:\n%s", out)
+	}
+	for _, want := range []string{
+		`"message":"lookup [REDACTED:email] failed"`,
+		`"error":"user [REDACTED:email] not found"`,
+		`"errorVerbose":"user [REDACTED:email] not found\n`,
+		"TestRedactErrorKeepsVerbose",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %s in:\n%s", want, out)
+		}
+	}
+}
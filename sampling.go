@@ -0,0 +1,49 @@
+package log
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rizanw/go-log/logger"
+)
+
+// maxSampleSummaries is maximum number of most suppressed logs printed in a summary log
+const maxSampleSummaries = 10
+
+// summarize writes a log reporting how many logs were suppressed since the last summary
+func (l *activeLogger) summarize() {
+	if l.sampler == nil {
+		return
+	}
+
+	summaries := l.sampler.Summary()
+	if len(summaries) == 0 {
+		return
+	}
+
+	var total uint64
+	for _, summary := range summaries {
+		total += summary.Count
+	}
+
+	entries := make([]map[string]interface{}, 0, maxSampleSummaries)
+	for i, summary := range summaries {
+		if i == maxSampleSummaries {
+			break
+		}
+		entries = append(entries, summary.Metadata())
+	}
+
+	// written regardless of minimum level, suppressed logs must not disappear silently at e.g. error level
+	l.logger.Write(logger.Entry{
+		Time:  time.Now(),
+		Level: WarnLevel,
+		Field: logger.Field{
+			Metadata: map[string]interface{}{
+				"suppressed": total,
+				"entries":    entries,
+			},
+		},
+		Message: fmt.Sprintf("[log] %d repeated logs suppressed by sampling", total),
+	})
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const summaryMessage = "repeated logs suppressed by sampling"
+
+func samplingConfig(engine Engine, path string) *Config {
+	return &Config{
+		Engine:                engine,
+		FilePath:              path,
+		UseJSON:               true,
+		SampleFirst:           1,
+		SampleInterval:        time.Minute,
+		SampleSummaryInterval: time.Hour,
+	}
+}
+
+// writeSummaryThenExit is the helper process of TestSummaryBeforeFatal
+func writeSummaryThenExit(path string, engine Engine) {
+	if err := SetConfig(samplingConfig(engine, path)); err != nil {
+		panic(err)
+	}
+	for i := 0; i < 10; i++ {
+		Info(context.Background(), nil, nil, "sampled")
+	}
+	Fatal(context.Background(), nil, nil, "fatal exit")
+}
+
+func TestMain(m *testing.M) {
+	if path := os.Getenv("GO_LOG_FATAL_PATH"); path != "" {
+		engine, err := ParseEngine(os.Getenv("GO_LOG_FATAL_ENGINE"))
+		if err != nil {
+			panic(err)
+		}
+		writeSummaryThenExit(path, engine)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func TestSummaryBeforeFatal(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fatal.log")
+			cmd := exec.Command(os.Args[0], "-test.run=^$")
+			cmd.Env = append(os.Environ(), "GO_LOG_FATAL_PATH="+path, "GO_LOG_FATAL_ENGINE="+engine.String())
+			if err := cmd.Run(); err == nil {
+				t.Fatal("Fatal did not exit with an error code")
+			}
+
+			assertSummaryBefore(t, path, "fatal exit")
+		})
+	}
+}
+
+func TestSummaryBeforePanic(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "panic.log")
+			if err := SetConfig(samplingConfig(engine, path)); err != nil {
+				t.Fatal(err)
+			}
+			defer SetConfig(nil)
+
+			for i := 0; i < 10; i++ {
+				Info(context.Background(), nil, nil, "sampled")
+			}
+			func() {
+				defer func() { _ = recover() }()
+				With(nil).Panic(context.Background(), nil, nil, "panic exit")
+			}()
+			if err := Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			assertSummaryBefore(t, path, "panic exit")
+		})
+	}
+}
+
+// assertSummaryBefore asserts the sampling summary is written before the log of the message
+func assertSummaryBefore(t *testing.T, path string, message string) {
+	t.Helper()
+
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, last := strings.Index(string(out), summaryMessage), strings.Index(string(out), message)
+	if summary < 0 || last < 0 || summary > last {
+		t.Errorf("want sampling summary before %q, got:\n%s", message, out)
+	}
+	if !strings.Contains(string(out), "[log] 9 "+summaryMessage) {
+		t.Errorf("want 9 suppressed logs, got:\n%s", out)
+	}
+}
+
+func TestSummaryAboveMinimumLevel(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "summary.log")
+			config := samplingConfig(engine, path)
+			config.Level = ErrorLevel
+			config.SampleSummaryInterval = 10 * time.Millisecond
+			if err := SetConfig(config); err != nil {
+				t.Fatal(err)
+			}
+			defer SetConfig(nil)
+
+			for i := 0; i < 10; i++ {
+				Error(context.Background(), nil, nil, "sampled")
+			}
+
+			// the periodic summary is written while the minimum level is still error
+			deadline := time.Now().Add(time.Second)
+			for {
+				out := readFile(t, path)
+				if strings.Contains(out, "[log] 9 "+summaryMessage) {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("summary is not written at error level:\n%s", out)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler which writes slog records through the active go-log logger,
+// so libraries accepting *slog.Logger end up in the same output as log.Info & friends
+type SlogHandler struct {
+	attrs  map[string]interface{}
+	groups []string
+}
+
+// NewSlogHandler creates slog.Handler backed by go-log
+func NewSlogHandler() *SlogHandler {
+	return &SlogHandler{}
+}
+
+// NewSlogLogger creates *slog.Logger backed by go-log
+func NewSlogLogger() *slog.Logger {
+	return slog.New(NewSlogHandler())
+}
+
+// Enabled reports whether slog level reaches minimum log level,
+// records below it are enabled for a request when DebugOnError keeps them
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if minLevel, ok := GetCtxLevel(ctx); ok {
+		if fromSlogLevel(level) >= minLevel {
+			return true
+		}
+	} else if rlevel.Enabled(fromSlogLevel(level)) {
+		return true
+	}
+	if GetCtxRequestID(ctx) == "" {
+		return false
+	}
+
+	l := acquire()
+	defer l.release()
+	return l.buffer != nil
+}
+
+// Handle writes slog record as metadata, request_id, source & user_info are taken from the context
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var (
+		err      error
+		metadata = cloneAttrs(h.attrs)
+	)
+
+	if record.NumAttrs() > 0 {
+		target := groupAttrs(metadata, h.groups)
+		record.Attrs(func(attr slog.Attr) bool {
+			// error attribute is logged as the log error instead of metadata
+			if e, ok := attr.Value.Any().(error); ok && err == nil && len(h.groups) == 0 &&
+				(attr.Key == "err" || attr.Key == "error") {
+				err = e
+				return true
+			}
+			addAttr(target, attr)
+			return true
+		})
+	}
+
+	l := acquire()
+	defer l.release()
+
+	level := fromSlogLevel(record.Level)
+
+	fields := buildFields(ctx, metadata)
+	// the caller is the slog call site, not this handler
+	fields.PC = record.PC
+	if !l.admit(level, fields, err, record.Message) {
+		return nil
+	}
+
+	switch level {
+	case TraceLevel:
+		l.logger.Trace(fields, err, record.Message)
+	case DebugLevel:
+		l.logger.Debug(fields, err, record.Message)
+	case InfoLevel:
+		l.logger.Info(fields, err, record.Message)
+	case WarnLevel:
+		l.logger.Warn(fields, err, record.Message)
+	default:
+		l.logger.Error(fields, err, record.Message)
+	}
+	return nil
+}
+
+// WithAttrs returns a new handler carrying the attributes
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	attrsMap := cloneAttrs(h.attrs)
+	target := groupAttrs(attrsMap, h.groups)
+	for _, attr := range attrs {
+		addAttr(target, attr)
+	}
+
+	return &SlogHandler{
+		attrs:  attrsMap,
+		groups: h.groups,
+	}
+}
+
+// WithGroup returns a new handler which nests the next attributes under the group name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+
+	return &SlogHandler{
+		attrs:  h.attrs,
+		groups: append(groups, name),
+	}
+}
+
+// fromSlogLevel converts slog level into log level,
+// note: levels above error are logged as error so a library can't stop your app
+func fromSlogLevel(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return TraceLevel
+	case level < slog.LevelInfo:
+		return DebugLevel
+	case level < slog.LevelWarn:
+		return InfoLevel
+	case level < slog.LevelError:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
+// addAttr puts slog attribute into the map, groups are nested as map & errors are written as their message
+func addAttr(m map[string]interface{}, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		value := attr.Value.Any()
+		if err, ok := value.(error); ok {
+			// encoders print most errors as `{}`
+			value = err.Error()
+		}
+		m[attr.Key] = value
+		return
+	}
+
+	groupAttrs := attr.Value.Group()
+	if len(groupAttrs) == 0 {
+		return
+	}
+
+	target := m
+	if attr.Key != "" {
+		if group, ok := m[attr.Key].(map[string]interface{}); ok {
+			target = group
+		} else {
+			target = make(map[string]interface{}, len(groupAttrs))
+			m[attr.Key] = target
+		}
+	}
+	for _, groupAttr := range groupAttrs {
+		addAttr(target, groupAttr)
+	}
+}
+
+// groupAttrs returns the nested map of the groups, creating it if needed
+func groupAttrs(m map[string]interface{}, groups []string) map[string]interface{} {
+	for _, name := range groups {
+		group, ok := m[name].(map[string]interface{})
+		if !ok {
+			group = make(map[string]interface{})
+			m[name] = group
+		}
+		m = group
+	}
+	return m
+}
+
+// cloneAttrs deep copies nested attributes so derived handlers don't share maps
+func cloneAttrs(m map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = cloneAttrs(nested)
+		}
+		clone[key] = value
+	}
+	return clone
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/slogtest"
+)
+
+func slogConfig(t *testing.T, engine Engine, withCaller bool) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "slog.log")
+	if err := SetConfig(&Config{Engine: engine, FilePath: path, UseJSON: true, WithCaller: withCaller}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetConfig(nil) })
+	return path
+}
+
+func readLines(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(readFile(t, path)), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	return lines
+}
+
+func TestSlogHandlerConformance(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := slogConfig(t, engine, false)
+
+			err := slogtest.TestHandler(NewSlogHandler(), func() []map[string]any {
+				lines := readLines(t, path)
+				for _, line := range lines {
+					// go-log keys & attributes under metadata, in the shape slogtest expects
+					line[slog.TimeKey], line[slog.MessageKey] = line["timestamp"], line["message"]
+					metadata, _ := line["metadata"].(map[string]interface{})
+					for key, value := range metadata {
+						line[key] = value
+					}
+				}
+				return lines
+			})
+
+			var errs []error
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			} else if err != nil {
+				errs = []error{err}
+			}
+			for _, err := range errs {
+				// every go-log line has its own time, a zero record time is not kept
+				if strings.Contains(err.Error(), "zero Record.Time") {
+					continue
+				}
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestSlogHandlerErrorAttrs(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := slogConfig(t, engine, false)
+
+			NewSlogLogger().WithGroup("db").Error("query failed",
+				"cause", errors.New("connection reset"),
+				slog.Group("retry", "err", fmt.Errorf("wrapped: %w", errors.New("timeout"))),
+			)
+
+			lines := readLines(t, path)
+			if len(lines) != 1 {
+				t.Fatalf("want 1 log, got %v", lines)
+			}
+			metadata, _ := lines[0]["metadata"].(map[string]interface{})
+			db, _ := metadata["db"].(map[string]interface{})
+			if db["cause"] != "connection reset" {
+				t.Errorf("cause = %v, want the error message", db["cause"])
+			}
+			if retry, _ := db["retry"].(map[string]interface{}); retry["err"] != "wrapped: timeout" {
+				t.Errorf("retry.err = %v, want the error message", db["retry"])
+			}
+		})
+	}
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := slogConfig(t, engine, true)
+
+			_, _, slogLine, _ := runtime.Caller(0)
+			NewSlogLogger().Info("slog caller")
+			_, _, logLine, _ := runtime.Caller(0)
+			Info(context.Background(), nil, nil, "log caller")
+
+			lines := readLines(t, path)
+			if len(lines) != 2 {
+				t.Fatalf("want 2 logs, got %v", lines)
+			}
+			for i, line := range []int{slogLine + 1, logLine + 1} {
+				want := fmt.Sprintf("slog_test.go:%d", line)
+				if caller, _ := lines[i]["line"].(string); !strings.HasSuffix(caller, want) {
+					t.Errorf("%v caller = %q, want %s", lines[i]["message"], caller, want)
+				}
+			}
+		})
+	}
+}
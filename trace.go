@@ -0,0 +1,153 @@
+package log
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	KeyCtxTraceParent = "traceparent"
+
+	// HeaderTraceParent is W3C trace context header name
+	HeaderTraceParent = "traceparent"
+)
+
+const (
+	traceParentVersion = "00"
+	traceParentLength  = 55
+)
+
+// TraceParent is W3C trace context carried by `traceparent` header,
+// e.g. `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`
+type TraceParent struct {
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+}
+
+// ParseTraceParent parses `traceparent` header value
+func ParseTraceParent(header string) (TraceParent, error) {
+	var (
+		traceParent TraceParent
+		value       = strings.TrimSpace(header)
+		parts       = strings.Split(value, "-")
+	)
+
+	if len(parts) < 4 {
+		return traceParent, fmt.Errorf("invalid traceparent: %q", header)
+	}
+
+	version := parts[0]
+	if !isHex(version, 2) || version == "ff" {
+		return traceParent, fmt.Errorf("invalid traceparent version: %q", version)
+	}
+	// version 00 has exactly 4 parts, future versions may append more
+	if version == traceParentVersion && (len(parts) != 4 || len(value) != traceParentLength) {
+		return traceParent, fmt.Errorf("invalid traceparent: %q", header)
+	}
+
+	traceParent = TraceParent{
+		TraceID:    parts[1],
+		SpanID:     parts[2],
+		TraceFlags: parts[3],
+	}
+	if !isHex(traceParent.TraceID, 32) || isZero(traceParent.TraceID) {
+		return TraceParent{}, fmt.Errorf("invalid trace id: %q", traceParent.TraceID)
+	}
+	if !isHex(traceParent.SpanID, 16) || isZero(traceParent.SpanID) {
+		return TraceParent{}, fmt.Errorf("invalid span id: %q", traceParent.SpanID)
+	}
+	if !isHex(traceParent.TraceFlags, 2) {
+		return TraceParent{}, fmt.Errorf("invalid trace flags: %q", traceParent.TraceFlags)
+	}
+
+	return traceParent, nil
+}
+
+// String formats trace parent as `traceparent` header value
+func (t TraceParent) String() string {
+	return strings.Join([]string{traceParentVersion, t.TraceID, t.SpanID, t.TraceFlags}, "-")
+}
+
+// IsValid reports whether trace parent has trace id & span id
+func (t TraceParent) IsValid() bool {
+	return t.TraceID != "" && t.SpanID != ""
+}
+
+// IsSampled reports whether sampled flag is set
+func (t TraceParent) IsSampled() bool {
+	flags, err := hex.DecodeString(t.TraceFlags)
+	return err == nil && len(flags) == 1 && flags[0]&0x01 == 0x01
+}
+
+// SetCtxTraceParent sets parsed `traceparent` header to context
+func SetCtxTraceParent(ctx context.Context, traceParent TraceParent) context.Context {
+	if !traceParent.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, KeyCtxTraceParent, traceParent)
+}
+
+// GetCtxTraceParent returns trace parent from OpenTelemetry span of the context,
+// or the one set by SetCtxTraceParent
+func GetCtxTraceParent(ctx context.Context) (TraceParent, bool) {
+	if ctx == nil {
+		return TraceParent{}, false
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		return TraceParent{
+			TraceID:    spanContext.TraceID().String(),
+			SpanID:     spanContext.SpanID().String(),
+			TraceFlags: spanContext.TraceFlags().String(),
+		}, true
+	}
+
+	if traceParent, ok := ctx.Value(KeyCtxTraceParent).(TraceParent); ok {
+		return traceParent, true
+	}
+	return TraceParent{}, false
+}
+
+// ExtractTraceParent parses `traceparent` header of the request into context
+func ExtractTraceParent(ctx context.Context, header http.Header) (context.Context, error) {
+	value := header.Get(HeaderTraceParent)
+	if value == "" {
+		return ctx, errors.New("traceparent header not found")
+	}
+
+	traceParent, err := ParseTraceParent(value)
+	if err != nil {
+		return ctx, err
+	}
+	return SetCtxTraceParent(ctx, traceParent), nil
+}
+
+// InjectTraceParent sets `traceparent` header from trace parent of the context
+func InjectTraceParent(ctx context.Context, header http.Header) {
+	if traceParent, ok := GetCtxTraceParent(ctx); ok {
+		header.Set(HeaderTraceParent, traceParent.String())
+	}
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID      = "00f067aa0ba902b7"
+	testTraceParent = "00-" + testTraceID + "-" + testSpanID + "-01"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    TraceParent
+		wantErr bool
+	}{
+		{name: "valid", header: testTraceParent, want: TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "01"}},
+		{name: "not sampled", header: "00-" + testTraceID + "-" + testSpanID + "-00", want: TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "00"}},
+		{name: "surrounding whitespace", header: " \t" + testTraceParent + " ", want: TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "01"}},
+		{name: "future version suffix", header: "cc-" + testTraceID + "-" + testSpanID + "-01-what-the-future-holds", want: TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "01"}},
+		{name: "future version", header: "01-" + testTraceID + "-" + testSpanID + "-01", want: TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "01"}},
+
+		{name: "empty", header: "", wantErr: true},
+		{name: "missing flags", header: "00-" + testTraceID + "-" + testSpanID, wantErr: true},
+		{name: "version ff", header: "ff-" + testTraceID + "-" + testSpanID + "-01", wantErr: true},
+		{name: "version not hex", header: "0x-" + testTraceID + "-" + testSpanID + "-01", wantErr: true},
+		{name: "version too long", header: "000-" + testTraceID + "-" + testSpanID + "-01", wantErr: true},
+		{name: "version 00 suffix", header: testTraceParent + "-extra", wantErr: true},
+		{name: "zero trace id", header: "00-00000000000000000000000000000000-" + testSpanID + "-01", wantErr: true},
+		{name: "zero span id", header: "00-" + testTraceID + "-0000000000000000-01", wantErr: true},
+		{name: "uppercase trace id", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01", wantErr: true},
+		{name: "short span id", header: "00-" + testTraceID + "-00f067aa0ba902-01", wantErr: true},
+		{name: "invalid flags", header: "00-" + testTraceID + "-" + testSpanID + "-0g", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTraceParent(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTraceParent(%q) error = %v, wantErr %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTraceParent(%q) = %+v, want %+v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceParentFlags(t *testing.T) {
+	traceParent := TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "01"}
+	if got := traceParent.String(); got != testTraceParent {
+		t.Errorf("String() = %q, want %q", got, testTraceParent)
+	}
+	if !traceParent.IsSampled() {
+		t.Error("IsSampled() of flags 01 = false")
+	}
+	if (TraceParent{TraceID: testTraceID, SpanID: testSpanID, TraceFlags: "02"}).IsSampled() {
+		t.Error("IsSampled() of flags 02 = true")
+	}
+	if (TraceParent{TraceID: testTraceID}).IsValid() {
+		t.Error("IsValid() without span id = true")
+	}
+}
+
+func TestExtractInjectTraceParent(t *testing.T) {
+	incoming := http.Header{}
+	incoming.Set(HeaderTraceParent, testTraceParent)
+
+	ctx, err := ExtractTraceParent(context.Background(), incoming)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outgoing := http.Header{}
+	InjectTraceParent(ctx, outgoing)
+	if got := outgoing.Get(HeaderTraceParent); got != testTraceParent {
+		t.Errorf("injected traceparent = %q, want %q", got, testTraceParent)
+	}
+
+	// the span of OpenTelemetry takes precedence over the extracted header
+	spanID := trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	traceID, _ := trace.TraceIDFromHex(testTraceID)
+	spanCtx := trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	InjectTraceParent(spanCtx, outgoing)
+	if want := "00-" + testTraceID + "-0102030405060708-00"; outgoing.Get(HeaderTraceParent) != want {
+		t.Errorf("injected traceparent = %q, want %q", outgoing.Get(HeaderTraceParent), want)
+	}
+
+	if _, err := ExtractTraceParent(context.Background(), http.Header{}); err == nil {
+		t.Error("ExtractTraceParent() without header returns no error")
+	}
+	incoming.Set(HeaderTraceParent, "invalid")
+	if ctx, err := ExtractTraceParent(context.Background(), incoming); err == nil {
+		t.Error("ExtractTraceParent() of an invalid header returns no error")
+	} else if _, ok := GetCtxTraceParent(ctx); ok {
+		t.Error("invalid traceparent is set into the context")
+	}
+
+	empty := http.Header{}
+	InjectTraceParent(context.Background(), empty)
+	if len(empty) != 0 {
+		t.Errorf("InjectTraceParent() without trace = %v, want no header", empty)
+	}
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex(testTraceID)
+	spanID, _ := trace.SpanIDFromHex(testSpanID)
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	headerCtx := SetCtxTraceParent(context.Background(), TraceParent{TraceID: testTraceID, SpanID: "1111111111111111", TraceFlags: "00"})
+
+	for _, engine := range []Engine{Zerolog, Zap, Slog} {
+		t.Run(engine.String(), func(t *testing.T) {
+			path := slogConfig(t, engine, false)
+
+			Info(spanCtx, nil, nil, "otel span")
+			Info(headerCtx, nil, nil, "traceparent header")
+			Info(context.Background(), nil, nil, "no trace")
+
+			lines := readLines(t, path)
+			if len(lines) != 3 {
+				t.Fatalf("want 3 logs, got %v", lines)
+			}
+			for i, want := range [][3]string{{testTraceID, testSpanID, "01"}, {testTraceID, "1111111111111111", "00"}} {
+				got := [3]interface{}{lines[i]["trace_id"], lines[i]["span_id"], lines[i]["trace_flags"]}
+				if got != [3]interface{}{want[0], want[1], want[2]} {
+					t.Errorf("%v trace = %v, want %v", lines[i]["message"], got, want)
+				}
+			}
+			if _, ok := lines[2]["trace_id"]; ok {
+				t.Errorf("log without trace has trace id: %v", lines[2])
+			}
+		})
+	}
+}